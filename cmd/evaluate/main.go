@@ -0,0 +1,127 @@
+// Command evaluate measures search relevance against a judgment file using
+// the local search index, and fails when the results regress from a saved
+// baseline.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/anugrahsputra/go-quran-api/config"
+	"github.com/anugrahsputra/go-quran-api/internal/repository"
+	"github.com/anugrahsputra/go-quran-api/internal/service"
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	judgmentsPath := flag.String("judgments", "cmd/evaluate/judgments.json", "JSON file of queries and their expected verses")
+	k := flag.Int("k", 10, "Number of hits evaluated per query")
+	baselinePath := flag.String("baseline", "", "Baseline report to compare against")
+	save := flag.Bool("save", false, "Write the report to -baseline instead of comparing")
+	tolerance := flag.Float64("tolerance", 0.01, "Largest metric drop from the baseline that is not a regression")
+	flag.Parse()
+
+	if *k < 1 {
+		log.Fatal("-k must be at least 1")
+	}
+	if *save && *baselinePath == "" {
+		log.Fatal("-save needs a -baseline path")
+	}
+
+	_ = godotenv.Load()
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("failed to load config: %v", err)
+	}
+
+	judgments, err := service.LoadJudgments(*judgmentsPath)
+	if err != nil {
+		log.Fatalf("failed to load judgments: %v", err)
+	}
+
+	if _, err := os.Stat(cfg.SearchIndexPath); err != nil {
+		log.Fatalf("no search index at %s; run make reindex first", cfg.SearchIndexPath)
+	}
+	searchRepo, err := repository.NewQuranSearchRepository(cfg)
+	if err != nil {
+		log.Fatalf("failed to open search index: %v", err)
+	}
+	if count, err := searchRepo.GetDocCount(); err != nil || count == 0 {
+		log.Fatalf("search index at %s is empty; run make reindex first", cfg.SearchIndexPath)
+	}
+	synonyms, err := service.NewSynonymStore(cfg.Search.SynonymsPath)
+	if err != nil {
+		log.Fatalf("failed to load search synonyms: %v", err)
+	}
+	translationRepo, err := repository.NewTranslationRepository(cfg)
+	if err != nil {
+		log.Fatalf("failed to load translations: %v", err)
+	}
+	translationService := service.NewTranslationService(translationRepo)
+	// Searching needs neither the Kemenag API nor the tafsir index, and
+	// results must come from the index rather than the cache. Evaluation
+	// queries are kept out of the search analytics.
+	searchService := service.NewQuranSearchService(nil, nil, searchRepo, nil, synonyms, translationService, nil, nil)
+
+	report, err := service.EvaluateRelevance(searchService.Search, judgments, *k)
+	if err != nil {
+		log.Fatalf("evaluation failed: %v", err)
+	}
+	printReport(report)
+
+	if *baselinePath == "" {
+		return
+	}
+	if *save {
+		if err := service.SaveEvaluationReport(*baselinePath, report); err != nil {
+			log.Fatalf("failed to save baseline: %v", err)
+		}
+		fmt.Printf("\nBaseline saved to %s\n", *baselinePath)
+		return
+	}
+
+	baseline, err := service.LoadEvaluationReport(*baselinePath)
+	if errors.Is(err, os.ErrNotExist) {
+		log.Fatalf("no baseline at %s; run make evaluate-baseline and commit it", *baselinePath)
+	}
+	if err != nil {
+		log.Fatalf("failed to load baseline: %v", err)
+	}
+	if baseline.K != report.K {
+		log.Fatalf("baseline was evaluated at k=%d, not k=%d", baseline.K, report.K)
+	}
+
+	regressions := service.CompareEvaluations(baseline, report, *tolerance)
+	if len(regressions) == 0 {
+		fmt.Printf("\nNo regressions against %s\n", *baselinePath)
+		return
+	}
+	fmt.Printf("\n%d regressions against %s:\n", len(regressions), *baselinePath)
+	for _, regression := range regressions {
+		fmt.Printf("  %s\n", regression)
+	}
+	os.Exit(1)
+}
+
+func printReport(report service.EvaluationReport) {
+	fmt.Printf("%-40s %8s %8s %8s %8s\n", "query", "P@k", "R@k", "RR", "nDCG")
+	for _, query := range report.Queries {
+		fmt.Printf("%-40s %8.4f %8.4f %8.4f %8.4f\n", truncate(query.Query, 40), query.Precision, query.Recall, query.ReciprocalRank, query.NDCG)
+		if len(query.Missing) > 0 {
+			fmt.Printf("  missing: %v\n", query.Missing)
+		}
+	}
+	fmt.Printf("\n%d queries at k=%d: precision %.4f, recall %.4f, MRR %.4f, nDCG %.4f\n",
+		len(report.Queries), report.K, report.Precision, report.Recall, report.MRR, report.NDCG)
+}
+
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
@@ -34,20 +34,34 @@ func main() {
 	reindex := flag.Bool("reindex", false, "Re-index the Quran data")
 	flag.Parse()
 
-	cfg := config.LoadConfig()
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("failed to load config: %v", err)
+	}
 
 	redisClient, err := redis.NewRedisClient(cfg)
 	if err != nil {
-		log.Printf("Warning: Redis connection failed: %v. Rate limiting will be disabled.", err)
+		log.Printf("Warning: Redis connection failed: %v. Rate limiting and caching will be disabled.", err)
 	}
 
 	surahRepo := repository.NewSurahRepository(cfg)
 	ayahRepo := repository.NewAyahRepository(cfg)
-	searchRepo, err := repository.NewQuranSearchRepository(cfg.SearchIndexPath)
+	searchRepo, tafsirRepo, err := repository.NewSearchRepositories(cfg)
 	if err != nil {
 		log.Fatalf("failed to create search repository: %v", err)
 	}
-	searchService := service.NewQuranSearchService(surahRepo, ayahRepo, searchRepo)
+	synonyms, err := service.NewSynonymStore(cfg.Search.SynonymsPath)
+	if err != nil {
+		log.Fatalf("failed to load search synonyms: %v", err)
+	}
+	translationRepo, err := repository.NewTranslationRepository(cfg)
+	if err != nil {
+		log.Fatalf("failed to load translations: %v", err)
+	}
+	translationService := service.NewTranslationService(translationRepo)
+	searchCache := service.NewSearchCache(redisClient, cfg.Search.CacheTTL)
+	searchAnalytics := service.NewSearchAnalytics(redisClient, cfg.Search.AnalyticsRetention, cfg.Search.SlowQuery)
+	searchService := service.NewQuranSearchService(surahRepo, ayahRepo, searchRepo, tafsirRepo, synonyms, translationService, searchCache, searchAnalytics)
 
 	if *reindex {
 		fmt.Println("Indexing Quran data...")
@@ -60,8 +74,12 @@ func main() {
 
 	count, err := searchRepo.GetDocCount()
 	if err == nil && count == 0 {
-		if os.Getenv("AUTO_INDEX") == "true" {
+		// An index in memory starts empty every time.
+		if os.Getenv("AUTO_INDEX") == "true" || cfg.Search.Backend == config.SearchBackendMemory {
 			log.Println("Search index is empty. Starting automatic indexing in background...")
+			if cfg.Search.Backend == config.SearchBackendMemory {
+				log.Println("The memory backend refetches every surah and verse from upstream; search returns no results until indexing completes.")
+			}
 			go func() {
 				if err := searchService.IndexQuran(); err != nil {
 					log.Printf("Automatic indexing failed: %v", err)
@@ -74,14 +92,20 @@ func main() {
 			log.Println("To populate the index, run with -reindex flag or set AUTO_INDEX=true environment variable.")
 		}
 	}
+	if tafsirRepo == nil {
+		log.Println("Tafsir paragraph search is not available with the memory search backend.")
+	} else if tafsirCount, err := tafsirRepo.GetDocCount(); err == nil && tafsirCount == 0 && count > 0 {
+		log.Println("Warning: Tafsir paragraph index is empty. Reindex to enable tafsir search.")
+	}
 
 	r := router.SetupRoute(router.RouterDeps{
-		Cfg:           cfg,
-		SurahRepo:     surahRepo,
-		AyahRepo:      ayahRepo,
-		SearchRepo:    searchRepo,
-		SearchService: searchService,
-		RedisClient:   redisClient,
+		Cfg:                cfg,
+		SurahRepo:          surahRepo,
+		AyahRepo:           ayahRepo,
+		SearchRepo:         searchRepo,
+		SearchService:      searchService,
+		TranslationService: translationService,
+		RedisClient:        redisClient,
 	})
 
 	srv := &http.Server{
@@ -1,16 +1,62 @@
 package config
 
 import (
+	"fmt"
+	"path/filepath"
+	"time"
+
 	"github.com/anugrahsputra/go-quran-api/utils/helper"
 )
 
 type Config struct {
 	Port            string
 	SearchIndexPath string
+	// TranslationsDir holds the translation files served besides the
+	// default one. Empty serves only the default translation.
+	TranslationsDir string
+	Search          SearchConfig
 	ExternalUrl     ExternalUrl
 	Redis           RedisConfig
 }
 
+// Search backends selectable with SEARCH_BACKEND.
+const (
+	SearchBackendBleve  = "bleve"
+	SearchBackendMemory = "memory"
+)
+
+type SearchConfig struct {
+	// Backend is SearchBackendBleve for indexes on disk or
+	// SearchBackendMemory for an index held in memory, which has to be
+	// filled on every start and has no tafsir paragraph search.
+	Backend string
+	// Fuzziness is the default edit distance used when matching the
+	// Translation and Latin fields. Zero disables fuzzy matching.
+	Fuzziness int
+	// SynonymsPath is an optional file of synonym groups used in addition
+	// to the built-in list.
+	SynonymsPath string
+	// TafsirIndexPath is the index of tafsir paragraphs.
+	TafsirIndexPath string
+	// Boosts overrides the default field weights, keyed by search field
+	// or "ngram" for partial-word matches.
+	Boosts map[string]float64
+	// NgramMin and NgramMax bound the partial-word grams. They only take
+	// effect when the index is created.
+	NgramMin int
+	NgramMax int
+	// ScoringModel is "tfidf" or "bm25".
+	ScoringModel string
+	// CacheTTL is how long search results are cached in Redis. Zero
+	// disables the cache.
+	CacheTTL time.Duration
+	// AnalyticsRetention is how long search analytics are kept in Redis.
+	// Zero disables them.
+	AnalyticsRetention time.Duration
+	// SlowQuery is the latency from which a search is reported as slow.
+	SlowQuery time.Duration
+}
+
 type ExternalUrl struct {
 	KemenagApi    string
 	PrayerTimeApi string
@@ -23,10 +69,39 @@ type RedisConfig struct {
 	DB       string
 }
 
-func LoadConfig() *Config {
+// LoadConfig reads the configuration from the environment, failing on
+// settings that would only break requests later.
+func LoadConfig() (*Config, error) {
+	searchIndexPath := helper.GetEnv("SEARCH_INDEX_PATH", "quran.bleve")
+
+	// bleve rejects fuzzy queries with a larger edit distance.
+	fuzziness := helper.GetEnvInt("SEARCH_FUZZINESS", 1)
+	if fuzziness < 0 || fuzziness > 2 {
+		return nil, fmt.Errorf("invalid SEARCH_FUZZINESS %d: must be 0, 1 or 2", fuzziness)
+	}
+	boosts, err := helper.GetEnvFloatMap("SEARCH_BOOSTS")
+	if err != nil {
+		return nil, err
+	}
+
 	return &Config{
 		Port:            helper.GetEnv("PORT", "8080"),
-		SearchIndexPath: helper.GetEnv("SEARCH_INDEX_PATH", "quran.bleve"),
+		SearchIndexPath: searchIndexPath,
+		TranslationsDir: helper.GetEnv("TRANSLATIONS_DIR", ""),
+		Search: SearchConfig{
+			Backend:      helper.GetEnv("SEARCH_BACKEND", SearchBackendBleve),
+			Fuzziness:    fuzziness,
+			SynonymsPath: helper.GetEnv("SEARCH_SYNONYMS_PATH", ""),
+			// Kept next to the ayah index by default so both share a volume.
+			TafsirIndexPath:    helper.GetEnv("SEARCH_TAFSIR_INDEX_PATH", filepath.Join(filepath.Dir(searchIndexPath), "quran_tafsir.bleve")),
+			Boosts:             boosts,
+			NgramMin:           helper.GetEnvInt("SEARCH_NGRAM_MIN", 3),
+			NgramMax:           helper.GetEnvInt("SEARCH_NGRAM_MAX", 4),
+			ScoringModel:       helper.GetEnv("SEARCH_SCORING_MODEL", "tfidf"),
+			CacheTTL:           time.Duration(helper.GetEnvInt("SEARCH_CACHE_TTL", 600)) * time.Second,
+			AnalyticsRetention: time.Duration(helper.GetEnvInt("SEARCH_ANALYTICS_RETENTION_DAYS", 30)) * 24 * time.Hour,
+			SlowQuery:          time.Duration(helper.GetEnvInt("SEARCH_SLOW_QUERY_MS", 500)) * time.Millisecond,
+		},
 		ExternalUrl: ExternalUrl{
 			KemenagApi:    helper.GetEnv("KEMENAG_API", "https://web-api.qurankemenag.net"),
 			PrayerTimeApi: helper.GetEnv("PRAYER_TIME_API", "https://api.aladhan.com/v1"),
@@ -37,5 +112,5 @@ func LoadConfig() *Config {
 			Password: helper.GetEnv("REDIS_PASSWORD", ""),
 			DB:       helper.GetEnv("REDIS_DB", "0"),
 		},
-	}
+	}, nil
 }
@@ -0,0 +1,32 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfig_Fuzziness(t *testing.T) {
+	t.Setenv("SEARCH_FUZZINESS", "2")
+	cfg, err := LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, 2, cfg.Search.Fuzziness)
+
+	t.Setenv("SEARCH_FUZZINESS", "3")
+	_, err = LoadConfig()
+	assert.Error(t, err)
+}
+
+func TestLoadConfig_Boosts(t *testing.T) {
+	t.Setenv("SEARCH_BOOSTS", "Tafsir:4, ngram:0.5")
+	cfg, err := LoadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]float64{"tafsir": 4, "ngram": 0.5}, cfg.Search.Boosts)
+
+	for _, value := range []string{"tafsir=4", "tafsir:high", "tafsir:4,"} {
+		t.Setenv("SEARCH_BOOSTS", value)
+		_, err = LoadConfig()
+		assert.Error(t, err, value)
+	}
+}
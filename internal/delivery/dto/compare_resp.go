@@ -0,0 +1,29 @@
+package dto
+
+import "github.com/anugrahsputra/go-quran-api/internal/domain"
+
+type CompareResp struct {
+	Status  int         `json:"status"`
+	Message string      `json:"message"`
+	Meta    Meta        `json:"meta"`
+	Data    CompareData `json:"data"`
+}
+
+type CompareData struct {
+	SurahID      int                      `json:"surah_id"`
+	Arabic       string                   `json:"arabic"`
+	Latin        string                   `json:"latin"`
+	Location     string                   `json:"location"`
+	Translations []domain.TranslationInfo `json:"translations"`
+	Verses       []CompareVerse           `json:"verses"`
+}
+
+// CompareVerse holds a verse with its text in each requested translation,
+// keyed by translation ID. A translation that lacks the verse maps to "".
+type CompareVerse struct {
+	Id           int               `json:"id"`
+	Ayah         int               `json:"ayah"`
+	Arabic       string            `json:"arabic"`
+	Latin        string            `json:"latin"`
+	Translations map[string]string `json:"translations"`
+}
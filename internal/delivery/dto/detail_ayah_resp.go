@@ -1,20 +1,21 @@
 package dto
 
 type DetailAyahResp struct {
-	ID          int       `json:"id"`
-	SurahID     int       `json:"surah_id"`
-	Ayah        int       `json:"ayah"`
-	Page        int       `json:"page"`
-	QuarterHizb float32   `json:"quarter_hizb"`
-	Juz         int       `json:"juz"`
-	Manzil      int       `json:"manzil"`
-	Arabic      string    `json:"arabic"`
-	Kitabah     string    `json:"kitabah"`
-	Latin       string    `json:"latin"`
-	ArabicWords []string  `json:"arabic_words"`
-	Translation string    `json:"translation"`
-	Surah       SurahResp `json:"surah"`
-	Tafsir      Tafsir    `json:"tafsir"`
+	ID            int       `json:"id"`
+	SurahID       int       `json:"surah_id"`
+	Ayah          int       `json:"ayah"`
+	Page          int       `json:"page"`
+	QuarterHizb   float32   `json:"quarter_hizb"`
+	Juz           int       `json:"juz"`
+	Manzil        int       `json:"manzil"`
+	Arabic        string    `json:"arabic"`
+	Kitabah       string    `json:"kitabah"`
+	Latin         string    `json:"latin"`
+	ArabicWords   []string  `json:"arabic_words"`
+	Translation   string    `json:"translation"`
+	TranslationID string    `json:"translation_id"`
+	Surah         SurahResp `json:"surah"`
+	Tafsir        Tafsir    `json:"tafsir"`
 }
 
 type Tafsir struct {
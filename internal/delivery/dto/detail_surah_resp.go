@@ -15,6 +15,7 @@ type SurahDetailData struct {
 	Transliteration string  `json:"transliteration"`
 	Location        string  `json:"location"`
 	Audio           string  `json:"audio"`
+	TranslationID   string  `json:"translation_id"`
 	Verses          []Verse `json:"verses"`
 }
 
@@ -0,0 +1,8 @@
+package dto
+
+import "github.com/anugrahsputra/go-quran-api/internal/domain"
+
+type RelatedData struct {
+	Source  domain.SearchHit      `json:"source"`
+	Related []domain.RelatedVerse `json:"related"`
+}
@@ -16,4 +16,6 @@ type Meta struct {
 	Page       int `json:"page"`
 	Limit      int `json:"limit"`
 	TotalPages int `json:"total_pages"`
+	// NextCursor continues a search after this page, when it has more.
+	NextCursor string `json:"next_cursor,omitempty"`
 }
@@ -0,0 +1,49 @@
+package dto
+
+type SearchClause struct {
+	Field  string  `json:"field" binding:"omitempty,oneof=translation latin tafsir topic wajiz sabab_nuzul kosakata conclusion kitabah surah juz page"`
+	Text   string  `json:"text" binding:"required,max=100"`
+	Phrase bool    `json:"phrase"`
+	Boost  float64 `json:"boost" binding:"omitempty,gt=0,lte=100"`
+}
+
+type SearchFilterRequest struct {
+	Surah    int    `json:"surah" binding:"omitempty,min=1,max=114"`
+	Juz      int    `json:"juz" binding:"omitempty,min=1,max=30"`
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Location string `json:"location"`
+}
+
+// SearchSort is a sort key of POST /search. mushaf and surah mean the same
+// orders as the sort parameter of GET /search.
+type SearchSort struct {
+	Field string `json:"field" binding:"required,oneof=score mushaf surah juz page"`
+	Order string `json:"order" binding:"omitempty,oneof=asc desc"`
+}
+
+// SearchRequest is the body of POST /search. Query accepts the same syntax
+// as the q parameter of GET /search and is combined with the clauses.
+type SearchRequest struct {
+	Query         string              `json:"query" binding:"max=100"`
+	Must          []SearchClause      `json:"must" binding:"max=20,dive"`
+	Should        []SearchClause      `json:"should" binding:"max=20,dive"`
+	MustNot       []SearchClause      `json:"must_not" binding:"max=20,dive"`
+	Fields        []string            `json:"fields" binding:"omitempty,dive,oneof=translation latin tafsir topic wajiz sabab_nuzul kosakata conclusion kitabah surah juz page"`
+	Boosts        map[string]float64  `json:"boosts" binding:"omitempty,dive,keys,oneof=translation latin tafsir topic wajiz sabab_nuzul kosakata conclusion kitabah surah juz page ngram,endkeys,gt=0,lte=100"`
+	Filter        SearchFilterRequest `json:"filter"`
+	Sort          []SearchSort        `json:"sort" binding:"max=4,dive"`
+	Page          int                 `json:"page" binding:"omitempty,min=1"`
+	Limit         int                 `json:"limit" binding:"omitempty,min=1,max=100"`
+	Highlight     string              `json:"highlight" binding:"omitempty,oneof=html plain"`
+	IncludeTafsir *bool               `json:"include_tafsir"`
+	Facets        []string            `json:"facets" binding:"omitempty,dive,oneof=surah juz topic"`
+	FacetSize     int                 `json:"facet_size" binding:"omitempty,min=1,max=114"`
+	Fuzziness     *int                `json:"fuzziness" binding:"omitempty,min=0,max=2"`
+	Lang          string              `json:"lang" binding:"max=10"`
+	Translation   string              `json:"translation" binding:"max=50"`
+	QueryLang     string              `json:"query_lang" binding:"max=10"`
+	ScoringModel  string              `json:"scoring_model" binding:"omitempty,oneof=tfidf bm25"`
+	Explain       bool                `json:"explain"`
+	Cursor        string              `json:"cursor" binding:"max=1000"`
+}
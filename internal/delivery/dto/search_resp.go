@@ -5,9 +5,30 @@ import (
 )
 
 type SearchResponse struct {
-	Code    int                   `json:"code"`
-	Status  string                `json:"status"`
-	Message string                `json:"message"`
-	Meta    Meta                  `json:"meta"`
-	Data    []domain.SearchedAyah `json:"data"`
+	Code    int    `json:"code"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
+	Meta    Meta   `json:"meta"`
+	// QueryLanguage is the given or detected language of the query.
+	QueryLanguage string                         `json:"query_language,omitempty"`
+	Data          []domain.SearchHit             `json:"data"`
+	Facets        map[string][]domain.FacetCount `json:"facets,omitempty"`
+	Suggestions   []string                       `json:"suggestions,omitempty"`
+	// QueryTree is the parsed query, returned with explain.
+	QueryTree any `json:"query_tree,omitempty"`
+}
+
+type SuggestResponse struct {
+	Code    int                 `json:"code"`
+	Status  string              `json:"status"`
+	Message string              `json:"message"`
+	Data    []domain.Completion `json:"data"`
+}
+
+type TafsirSearchResponse struct {
+	Code    int                `json:"code"`
+	Status  string             `json:"status"`
+	Message string             `json:"message"`
+	Meta    Meta               `json:"meta"`
+	Data    []domain.TafsirHit `json:"data"`
 }
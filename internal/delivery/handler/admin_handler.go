@@ -1,11 +1,15 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"strconv"
 
 	"github.com/anugrahsputra/go-quran-api/internal/delivery/dto"
 	"github.com/anugrahsputra/go-quran-api/internal/service"
+	"github.com/anugrahsputra/go-quran-api/utils/helper"
 	"github.com/gin-gonic/gin"
 )
 
@@ -37,3 +41,78 @@ func (h *AdminHandler) Reindex(c *gin.Context) {
 		},
 	})
 }
+
+func (h *AdminHandler) ReloadSynonyms(c *gin.Context) {
+	groups, err := h.searchAyahService.ReloadSynonyms()
+	if err != nil {
+		log.Printf("Synonym reload error: %v", err)
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Status:  http.StatusInternalServerError,
+			Message: helper.SanitizeError(err),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.Response{
+		Status:  http.StatusOK,
+		Message: "Synonyms reloaded",
+		Data: map[string]interface{}{
+			"groups": groups,
+		},
+	})
+}
+
+// SearchCacheStats reports the search cache hits and misses of this
+// instance.
+func (h *AdminHandler) SearchCacheStats(c *gin.Context) {
+	c.JSON(http.StatusOK, dto.Response{
+		Status:  http.StatusOK,
+		Message: "Success",
+		Data:    h.searchAyahService.SearchCacheStats(),
+	})
+}
+
+// SearchAnalytics reports the most frequent, zero-result and slowest
+// search queries over a window.
+func (h *AdminHandler) SearchAnalytics(c *gin.Context) {
+	window := c.DefaultQuery("window", "24h")
+	if _, ok := service.SearchAnalyticsWindows[window]; !ok {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  http.StatusBadRequest,
+			Message: fmt.Sprintf("invalid window %q: must be 1h, 24h, 7d or 30d", window),
+		})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 || limit > 100 {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  http.StatusBadRequest,
+			Message: "invalid limit: must be between 1 and 100",
+		})
+		return
+	}
+
+	report, err := h.searchAyahService.SearchAnalytics(window, limit)
+	if errors.Is(err, service.ErrSearchAnalyticsDisabled) {
+		c.JSON(http.StatusServiceUnavailable, dto.ErrorResponse{
+			Status:  http.StatusServiceUnavailable,
+			Message: err.Error(),
+		})
+		return
+	}
+	if err != nil {
+		log.Printf("Search analytics error: %v", err)
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Status:  http.StatusInternalServerError,
+			Message: helper.SanitizeError(err),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.Response{
+		Status:  http.StatusOK,
+		Message: "Success",
+		Data:    report,
+	})
+}
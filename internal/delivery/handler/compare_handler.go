@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/anugrahsputra/go-quran-api/internal/delivery/dto"
+	"github.com/anugrahsputra/go-quran-api/internal/domain"
+	"github.com/anugrahsputra/go-quran-api/internal/service"
+	"github.com/anugrahsputra/go-quran-api/utils/helper"
+	"github.com/gin-gonic/gin"
+)
+
+const maxCompareTranslations = 10
+
+type CompareHandler struct {
+	compareService     service.CompareService
+	translationService service.ITranslationService
+}
+
+func NewCompareHandler(cs service.CompareService, ts service.ITranslationService) *CompareHandler {
+	return &CompareHandler{
+		compareService:     cs,
+		translationService: ts,
+	}
+}
+
+// Compare serves a verse reference ("2:255", "ayat kursi") as that single
+// verse and a surah reference ("2", "al-baqarah") as a page of its verses.
+func (h *CompareHandler) Compare(c *gin.Context) {
+	refStr := c.Param("ref")
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 10
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	var surah int
+	single := false
+	if ref, ok := service.ParseReference(refStr); ok {
+		surah, page, limit, single = ref.Surah, ref.Ayah, 1, true
+	} else if number, ok := service.LookupSurah(refStr); ok {
+		surah = number
+	} else {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  http.StatusBadRequest,
+			Message: fmt.Sprintf("invalid reference %q: expected a surah or a verse such as 2:255", refStr),
+		})
+		return
+	}
+
+	translations, err := h.parseTranslations(c.Query("translations"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	logger.Infof(
+		"HTTP %s %s | IP: %s | Params: ref=%s, translations=%d, page=%d, limit=%d | UA: %s",
+		c.Request.Method,
+		c.Request.URL.Path,
+		c.ClientIP(),
+		refStr,
+		len(translations),
+		page,
+		limit,
+		c.Request.UserAgent(),
+	)
+
+	data, totalVerses, totalPages, err := h.compareService.Compare(c.Request.Context(), surah, page, limit, translations)
+	if err != nil {
+		logger.Errorf("Error comparing translations: %s", err)
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Status:  http.StatusInternalServerError,
+			Message: helper.SanitizeError(err),
+		})
+		return
+	}
+
+	meta := dto.Meta{
+		Total:      totalVerses,
+		Page:       page,
+		Limit:      limit,
+		TotalPages: totalPages,
+	}
+	if single {
+		meta = dto.Meta{Total: 1, Page: 1, Limit: 1, TotalPages: 1}
+	}
+
+	c.JSON(http.StatusOK, dto.CompareResp{
+		Status:  http.StatusOK,
+		Message: "success",
+		Meta:    meta,
+		Data:    data,
+	})
+}
+
+// parseTranslations resolves a comma-separated list of translation IDs,
+// defaulting to every available translation.
+func (h *CompareHandler) parseTranslations(value string) ([]domain.TranslationInfo, error) {
+	if strings.TrimSpace(value) == "" {
+		all := h.translationService.List()
+		if len(all) > maxCompareTranslations {
+			all = all[:maxCompareTranslations]
+		}
+		return all, nil
+	}
+
+	var translations []domain.TranslationInfo
+	for _, id := range strings.Split(value, ",") {
+		id = strings.TrimSpace(id)
+		if id == "" {
+			continue
+		}
+		info, err := h.translationService.Resolve("", id)
+		if err != nil {
+			return nil, err
+		}
+		if !slices.Contains(translations, info) {
+			translations = append(translations, info)
+		}
+	}
+	if len(translations) > maxCompareTranslations {
+		return nil, fmt.Errorf("at most %d translations can be compared", maxCompareTranslations)
+	}
+	return translations, nil
+}
@@ -11,12 +11,14 @@ import (
 )
 
 type DetailAyahHandler struct {
-	detailAyahService service.AyahService
+	detailAyahService  service.AyahService
+	translationService service.ITranslationService
 }
 
-func NewDetailAyahHandler(das service.AyahService) *DetailAyahHandler {
+func NewDetailAyahHandler(das service.AyahService, ts service.ITranslationService) *DetailAyahHandler {
 	return &DetailAyahHandler{
-		detailAyahService: das,
+		detailAyahService:  das,
+		translationService: ts,
 	}
 }
 
@@ -31,6 +33,15 @@ func (s *DetailAyahHandler) GetDetailAyah(c *gin.Context) {
 		return
 	}
 
+	translation, err := resolveTranslation(c, s.translationService)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
 	logger.Infof(
 		"HTTP %s %s | IP: %s | Params: ayah_id=%d | UA: %s",
 		c.Request.Method,
@@ -40,7 +51,7 @@ func (s *DetailAyahHandler) GetDetailAyah(c *gin.Context) {
 		c.Request.UserAgent(),
 	)
 
-	response, err := s.detailAyahService.GetAyah(c.Request.Context(), ayahID)
+	response, err := s.detailAyahService.GetAyah(c.Request.Context(), ayahID, translation.ID)
 	if err != nil {
 		logger.Errorf("Error fetching ayah detail: %s", err)
 		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
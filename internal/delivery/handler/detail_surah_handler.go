@@ -11,12 +11,14 @@ import (
 )
 
 type DetailSurahHandler struct {
-	quranService service.SurahService
+	quranService       service.SurahService
+	translationService service.ITranslationService
 }
 
-func NewDetailSurahHandler(quranService service.SurahService) *DetailSurahHandler {
+func NewDetailSurahHandler(quranService service.SurahService, ts service.ITranslationService) *DetailSurahHandler {
 	return &DetailSurahHandler{
-		quranService: quranService,
+		quranService:       quranService,
+		translationService: ts,
 	}
 }
 
@@ -52,6 +54,15 @@ func (h *DetailSurahHandler) GetDetailSurah(c *gin.Context) {
 		limit = 100
 	}
 
+	translation, err := resolveTranslation(c, h.translationService)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
 	logger.Infof(
 		"HTTP %s %s | IP: %s | Params: surah_id=%d, page=%d, limit=%d | UA: %s",
 		c.Request.Method,
@@ -63,7 +74,7 @@ func (h *DetailSurahHandler) GetDetailSurah(c *gin.Context) {
 		c.Request.UserAgent(),
 	)
 
-	data, totalVerses, totalPages, err := h.quranService.GetSurahDetail(c.Request.Context(), surahID, page, limit)
+	data, totalVerses, totalPages, err := h.quranService.GetSurahDetail(c.Request.Context(), surahID, page, limit, translation.ID)
 	if err != nil {
 		logger.Errorf("Error fetching surah detail: %s", err)
 		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
@@ -6,7 +6,6 @@ import (
 	"testing"
 
 	"github.com/anugrahsputra/go-quran-api/internal/domain"
-	"github.com/blevesearch/bleve/v2"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -21,14 +20,34 @@ func (m *MockQuranSearchRepository) Index(ayahs []domain.SearchedAyah) error {
 	return args.Error(0)
 }
 
-func (m *MockQuranSearchRepository) Search(query string, page, limit int) (*bleve.SearchResult, error) {
-	args := m.Called(query, page, limit)
-	return args.Get(0).(*bleve.SearchResult), args.Error(1)
+func (m *MockQuranSearchRepository) Rebuild() (func(keep bool) error, error) {
+	args := m.Called()
+	return args.Get(0).(func(keep bool) error), args.Error(1)
+}
+
+func (m *MockQuranSearchRepository) Search(params domain.SearchParams) (*domain.IndexResults, error) {
+	args := m.Called(params)
+	return args.Get(0).(*domain.IndexResults), args.Error(1)
+}
+
+func (m *MockQuranSearchRepository) SpellingSuggestions(query string, limit int) ([]string, error) {
+	args := m.Called(query, limit)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockQuranSearchRepository) Complete(prefix string, limit int) ([]domain.Completion, error) {
+	args := m.Called(prefix, limit)
+	return args.Get(0).([]domain.Completion), args.Error(1)
 }
 
-func (m *MockQuranSearchRepository) GetDocument(id string) (map[string]any, error) {
+func (m *MockQuranSearchRepository) GetDocument(id string) (*domain.SearchedAyah, error) {
 	args := m.Called(id)
-	return args.Get(0).(map[string]any), args.Error(1)
+	return args.Get(0).(*domain.SearchedAyah), args.Error(1)
+}
+
+func (m *MockQuranSearchRepository) Related(id string, limit int) (*domain.IndexResults, error) {
+	args := m.Called(id, limit)
+	return args.Get(0).(*domain.IndexResults), args.Error(1)
 }
 
 func (m *MockQuranSearchRepository) GetDocCount() (uint64, error) {
@@ -41,6 +60,11 @@ func (m *MockQuranSearchRepository) IsHealthy() bool {
 	return args.Bool(0)
 }
 
+func (m *MockQuranSearchRepository) Close() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
 func TestPing(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -1,52 +1,39 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"slices"
 	"strconv"
+	"strings"
 
 	"github.com/anugrahsputra/go-quran-api/internal/delivery/dto"
+	"github.com/anugrahsputra/go-quran-api/internal/domain"
 	"github.com/anugrahsputra/go-quran-api/internal/service"
 	"github.com/anugrahsputra/go-quran-api/utils/helper"
+	"github.com/anugrahsputra/go-quran-api/utils/middleware"
 	"github.com/gin-gonic/gin"
 )
 
 type QuranSearchHandler struct {
 	quranSearchService service.IQuranSearchService
+	translationService service.ITranslationService
 }
 
-func NewQuranSearchHandler(quranSearchService service.IQuranSearchService) *QuranSearchHandler {
-	return &QuranSearchHandler{quranSearchService: quranSearchService}
+func NewQuranSearchHandler(quranSearchService service.IQuranSearchService, ts service.ITranslationService) *QuranSearchHandler {
+	return &QuranSearchHandler{quranSearchService: quranSearchService, translationService: ts}
 }
 
 func (h *QuranSearchHandler) Search(c *gin.Context) {
 	query := c.Query("q")
 	if query == "" {
-		c.JSON(http.StatusBadRequest, dto.SearchResponse{
-			Code:    http.StatusBadRequest,
-			Status:  "Bad Request",
-			Message: "Query parameter 'q' is required",
-			Meta: dto.Meta{
-				Total:      0,
-				Page:       1,
-				Limit:      10,
-				TotalPages: 0,
-			},
-		})
+		badSearchRequest(c, "Query parameter 'q' is required", 1, 10)
 		return
 	}
 
 	if len(query) > 100 {
-		c.JSON(http.StatusBadRequest, dto.SearchResponse{
-			Code:    http.StatusBadRequest,
-			Status:  "Bad Request",
-			Message: "Search query too long (max 100 characters)",
-			Meta: dto.Meta{
-				Total:      0,
-				Page:       1,
-				Limit:      10,
-				TotalPages: 0,
-			},
-		})
+		badSearchRequest(c, "Search query too long (max 100 characters)", 1, 10)
 		return
 	}
 
@@ -63,7 +50,91 @@ func (h *QuranSearchHandler) Search(c *gin.Context) {
 		limit = 100
 	}
 
-	ayahs, total, err := h.quranSearchService.Search(query, page, limit)
+	params, err := parseSearchParams(c)
+	if err != nil {
+		badSearchRequest(c, err.Error(), page, limit)
+		return
+	}
+	params.Translation, err = h.selectedTranslation(c.Query("lang"), c.Query("translation"))
+	if err != nil {
+		badSearchRequest(c, err.Error(), page, limit)
+		return
+	}
+
+	params.Query = query
+	params.Page = page
+	params.Limit = limit
+
+	h.search(c, params)
+}
+
+// AdvancedSearch runs a search described by a dto.SearchRequest body.
+func (h *QuranSearchHandler) AdvancedSearch(c *gin.Context) {
+	var req dto.SearchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		badSearchRequest(c, "Invalid request body: "+err.Error(), 1, 10)
+		return
+	}
+
+	params, err := searchRequestToParams(req)
+	if err != nil {
+		badSearchRequest(c, err.Error(), params.Page, params.Limit)
+		return
+	}
+
+	params.Translation, err = h.selectedTranslation(req.Lang, req.Translation)
+	if err != nil {
+		badSearchRequest(c, err.Error(), params.Page, params.Limit)
+		return
+	}
+
+	h.search(c, params)
+}
+
+// selectedTranslation resolves the translation a search is limited to. It
+// returns "" when neither lang nor id is given, which searches every
+// translation.
+func (h *QuranSearchHandler) selectedTranslation(lang, id string) (string, error) {
+	if lang == "" && id == "" {
+		return "", nil
+	}
+	translation, err := h.translationService.Resolve(lang, id)
+	if err != nil {
+		return "", err
+	}
+	return translation.ID, nil
+}
+
+func (h *QuranSearchHandler) search(c *gin.Context, params domain.SearchParams) {
+	page, limit := params.Page, params.Limit
+
+	// A scoring model override makes the search run alone, boosts reorder
+	// results and explanations expose the index internals, so all three are
+	// kept for admins tuning relevance.
+	if (params.ScoringModel != "" || len(params.Boosts) > 0 || params.Explain) && !middleware.IsAdmin(c) {
+		c.JSON(http.StatusForbidden, dto.SearchResponse{
+			Code:    http.StatusForbidden,
+			Status:  "Forbidden",
+			Message: "scoring_model, boosts and explain require a valid X-Admin-Token",
+			Meta:    dto.Meta{Page: page, Limit: limit},
+		})
+		return
+	}
+
+	// The verse a reference-shaped query points at leads the first page
+	// and is left out of the full-text hits of every page.
+	pinned := h.pinnedReference(params)
+	if pinned != nil {
+		params.Exclude = append(params.Exclude, domain.VerseRef{Surah: pinned.SurahNumber, Ayah: pinned.AyahNumber}.String())
+		params.Pinned = 1
+	}
+
+	results, err := h.quranSearchService.Search(params)
+	var syntaxErr *service.QuerySyntaxError
+	if errors.As(err, &syntaxErr) {
+		badSearchRequest(c, syntaxErr.Error(), page, limit)
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, dto.SearchResponse{
 			Code:    http.StatusInternalServerError,
@@ -79,6 +150,15 @@ func (h *QuranSearchHandler) Search(c *gin.Context) {
 		return
 	}
 
+	hits, total := results.Hits, results.Total
+	if pinned != nil {
+		total++
+		if page == 1 && params.Cursor == "" {
+			hits = append([]domain.SearchHit{*pinned}, hits...)
+			hits = hits[:min(len(hits), limit)]
+		}
+	}
+
 	totalPages := (total + limit - 1) / limit
 	if totalPages == 0 && total > 0 {
 		totalPages = 1
@@ -93,7 +173,396 @@ func (h *QuranSearchHandler) Search(c *gin.Context) {
 			Page:       page,
 			Limit:      limit,
 			TotalPages: totalPages,
+			NextCursor: results.NextCursor,
+		},
+		QueryLanguage: results.QueryLanguage,
+		Data:          hits,
+		Facets:        results.Facets,
+		Suggestions:   results.Suggestions,
+		QueryTree:     results.QueryTree,
+	})
+}
+
+func (h *QuranSearchHandler) Suggest(c *gin.Context) {
+	prefix := strings.TrimSpace(c.Query("q"))
+	if prefix == "" || len(prefix) > 100 {
+		c.JSON(http.StatusBadRequest, dto.SuggestResponse{
+			Code:    http.StatusBadRequest,
+			Status:  "Bad Request",
+			Message: "Query parameter 'q' is required (max 100 characters)",
+			Data:    []domain.Completion{},
+		})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if limit < 1 || limit > 20 {
+		limit = 10
+	}
+
+	completions, err := h.quranSearchService.Suggest(prefix, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.SuggestResponse{
+			Code:    http.StatusInternalServerError,
+			Status:  "Internal Server Error",
+			Message: helper.SanitizeError(err),
+			Data:    []domain.Completion{},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuggestResponse{
+		Code:    http.StatusOK,
+		Status:  "OK",
+		Message: "Success",
+		Data:    completions,
+	})
+}
+
+// pinnedReference returns the verse a reference-shaped query points at
+// (e.g. "2:255" or "ayat kursi"), or nil when the query is not a reference
+// or the verse is outside the search filter.
+func (h *QuranSearchHandler) pinnedReference(params domain.SearchParams) *domain.SearchHit {
+	if params.Query == "" {
+		return nil
+	}
+	pinned, err := h.quranSearchService.FindReference(params.Query, params.Translation)
+	if err != nil {
+		logger.Errorf("Error resolving search reference %q: %s", params.Query, err)
+		return nil
+	}
+	if pinned == nil || !params.Filter.Matches(pinned.SearchedAyah) {
+		return nil
+	}
+	if params.OmitTafsir {
+		pinned.Tafsir = ""
+	}
+	return pinned
+}
+
+// parseSearchParams reads the optional search parameters. Query, page and
+// limit are validated by the caller.
+func parseSearchParams(c *gin.Context) (domain.SearchParams, error) {
+	var params domain.SearchParams
+
+	filter, err := parseSearchFilter(c)
+	if err != nil {
+		return params, err
+	}
+	params.Filter = filter
+
+	if fields := c.Query("fields"); fields != "" {
+		params.Fields, err = parseFields(fields)
+		if err != nil {
+			return params, err
+		}
+	}
+
+	params.Highlight = c.Query("highlight")
+	if params.Highlight != "" && params.Highlight != domain.HighlightHTML && params.Highlight != domain.HighlightPlain {
+		return params, fmt.Errorf("invalid highlight %q: must be %s or %s", params.Highlight, domain.HighlightHTML, domain.HighlightPlain)
+	}
+
+	includeTafsir, err := strconv.ParseBool(c.DefaultQuery("include_tafsir", "true"))
+	if err != nil {
+		return params, errors.New("invalid include_tafsir: must be true or false")
+	}
+	params.OmitTafsir = !includeTafsir
+
+	if fuzziness := c.Query("fuzziness"); fuzziness != "" {
+		value, err := strconv.Atoi(fuzziness)
+		if err != nil || value < 0 || value > 2 {
+			return params, errors.New("invalid fuzziness: must be 0, 1 or 2")
+		}
+		params.Fuzziness = &value
+	}
+
+	if boosts := c.Query("boosts"); boosts != "" {
+		params.Boosts, err = parseBoosts(boosts)
+		if err != nil {
+			return params, err
+		}
+	}
+
+	params.ScoringModel = c.Query("scoring_model")
+	if params.ScoringModel != "" && params.ScoringModel != domain.ScoringTFIDF && params.ScoringModel != domain.ScoringBM25 {
+		return params, fmt.Errorf("invalid scoring_model %q: must be %s or %s", params.ScoringModel, domain.ScoringTFIDF, domain.ScoringBM25)
+	}
+
+	params.Cursor = c.Query("cursor")
+	if len(params.Cursor) > 1000 {
+		return params, errors.New("invalid cursor: too long")
+	}
+
+	if sort := c.Query("sort"); sort != "" {
+		params.Sort, err = parseSort(sort)
+		if err != nil {
+			return params, err
+		}
+	}
+
+	explain, err := strconv.ParseBool(c.DefaultQuery("explain", "false"))
+	if err != nil {
+		return params, errors.New("invalid explain: must be true or false")
+	}
+	params.Explain = explain
+
+	if queryLang := c.Query("query_lang"); queryLang != "" {
+		if len(queryLang) > 10 {
+			return params, errors.New("invalid query_lang: too long (max 10 characters)")
+		}
+		params.QueryLanguage = queryLang
+	}
+
+	if facets := c.Query("facets"); facets != "" {
+		params.Facets, err = parseFacets(facets)
+		if err != nil {
+			return params, err
+		}
+
+		params.FacetSize, err = strconv.Atoi(c.DefaultQuery("facet_size", "10"))
+		if err != nil || params.FacetSize < 1 || params.FacetSize > 114 {
+			return params, errors.New("invalid facet_size: must be between 1 and 114")
+		}
+	}
+
+	return params, nil
+}
+
+func parseFields(value string) ([]string, error) {
+	var fields []string
+	for _, field := range strings.Split(value, ",") {
+		field = strings.ToLower(strings.TrimSpace(field))
+		if !slices.Contains(domain.SearchFields, field) {
+			return nil, fmt.Errorf("invalid field %q: must be one of %s", field, strings.Join(domain.SearchFields, ", "))
+		}
+		if !slices.Contains(fields, field) {
+			fields = append(fields, field)
+		}
+	}
+	return fields, nil
+}
+
+// parseSort reads the sort parameter: relevance (the default), mushaf for
+// surah and ayah order, or surah for the best hits of each surah in surah
+// order. A leading "-" reverses mushaf and surah; score is an alias of
+// relevance, which is always best first.
+func parseSort(value string) ([]domain.SortField, error) {
+	name, reverse := strings.CutPrefix(strings.ToLower(strings.TrimSpace(value)), "-")
+	switch {
+	case name == "relevance" || name == domain.SortScore:
+		if reverse {
+			return nil, fmt.Errorf("invalid sort %q: relevance is always best first and cannot be reversed", value)
+		}
+		return []domain.SortField{{Field: domain.SortScore, Desc: true}}, nil
+	case name == sortMushaf || name == domain.SortSurah:
+		return sortFields(name, reverse), nil
+	default:
+		return nil, fmt.Errorf("invalid sort %q: must be relevance, mushaf or surah, with mushaf and surah optionally prefixed with -", value)
+	}
+}
+
+// sortMushaf is the sort name for surah and ayah order. The domain calls
+// that order SortSurah, while the API keeps surah for grouping by surah.
+const sortMushaf = "mushaf"
+
+// sortFields returns the order a sort name stands for in both GET and POST
+// searches: mushaf is surah and ayah order, and surah is surah order with
+// the best hits of each surah first.
+func sortFields(name string, desc bool) []domain.SortField {
+	switch name {
+	case sortMushaf:
+		return []domain.SortField{{Field: domain.SortSurah, Desc: desc}}
+	case domain.SortSurah:
+		return []domain.SortField{{Field: domain.SortSurahNumber, Desc: desc}, {Field: domain.SortScore, Desc: true}}
+	default:
+		return []domain.SortField{{Field: name, Desc: desc}}
+	}
+}
+
+// parseBoosts reads field weights such as "translation:2,ngram:0.5".
+func parseBoosts(value string) (map[string]float64, error) {
+	boosts := make(map[string]float64)
+	for _, pair := range strings.Split(value, ",") {
+		field, weight, ok := strings.Cut(pair, ":")
+		field = strings.ToLower(strings.TrimSpace(field))
+		if !ok || (field != domain.BoostNgram && !slices.Contains(domain.SearchFields, field)) {
+			return nil, fmt.Errorf("invalid boost %q: must be field:weight with a field of %s or %s", pair, strings.Join(domain.SearchFields, ", "), domain.BoostNgram)
+		}
+		boost, err := strconv.ParseFloat(strings.TrimSpace(weight), 64)
+		if err != nil || boost <= 0 || boost > 100 {
+			return nil, fmt.Errorf("invalid boost %q: weight must be greater than 0 and at most 100", pair)
+		}
+		boosts[field] = boost
+	}
+	return boosts, nil
+}
+
+func parseFacets(value string) ([]string, error) {
+	all := []string{domain.FacetSurah, domain.FacetJuz, domain.FacetTopic}
+	if value == "true" || value == "all" {
+		return all, nil
+	}
+
+	var facets []string
+	for _, facet := range strings.Split(value, ",") {
+		facet = strings.TrimSpace(facet)
+		if !slices.Contains(all, facet) {
+			return nil, fmt.Errorf("invalid facet %q: must be one of %s", facet, strings.Join(all, ", "))
+		}
+		if !slices.Contains(facets, facet) {
+			facets = append(facets, facet)
+		}
+	}
+	return facets, nil
+}
+
+// searchRequestToParams validates the parts of a search request body that
+// binding tags cannot express and converts it to domain.SearchParams.
+func searchRequestToParams(req dto.SearchRequest) (domain.SearchParams, error) {
+	params := domain.SearchParams{
+		Query:         strings.TrimSpace(req.Query),
+		Page:          req.Page,
+		Limit:         req.Limit,
+		Fields:        req.Fields,
+		Boosts:        req.Boosts,
+		Highlight:     req.Highlight,
+		OmitTafsir:    req.IncludeTafsir != nil && !*req.IncludeTafsir,
+		Facets:        req.Facets,
+		FacetSize:     req.FacetSize,
+		Fuzziness:     req.Fuzziness,
+		QueryLanguage: req.QueryLang,
+		ScoringModel:  req.ScoringModel,
+		Explain:       req.Explain,
+		Cursor:        req.Cursor,
+	}
+	if params.Page == 0 {
+		params.Page = 1
+	}
+	if params.Limit == 0 {
+		params.Limit = 10
+	}
+
+	groups := []struct {
+		occur   string
+		clauses []dto.SearchClause
+	}{
+		{domain.OccurMust, req.Must},
+		{domain.OccurShould, req.Should},
+		{domain.OccurMustNot, req.MustNot},
+	}
+	for _, group := range groups {
+		for _, clause := range group.clauses {
+			params.Clauses = append(params.Clauses, domain.QueryClause{
+				Field:  clause.Field,
+				Text:   clause.Text,
+				Phrase: clause.Phrase,
+				Occur:  group.occur,
+				Boost:  clause.Boost,
+			})
+		}
+	}
+	if params.Query == "" && !slices.ContainsFunc(params.Clauses, func(c domain.QueryClause) bool { return c.Occur != domain.OccurMustNot }) {
+		return params, errors.New("query or at least one must or should clause is required")
+	}
+
+	var surah, juz string
+	if req.Filter.Surah > 0 {
+		surah = strconv.Itoa(req.Filter.Surah)
+	}
+	if req.Filter.Juz > 0 {
+		juz = strconv.Itoa(req.Filter.Juz)
+	}
+	filter, err := newSearchFilter(surah, juz, req.Filter.From, req.Filter.To, req.Filter.Location)
+	if err != nil {
+		return params, err
+	}
+	params.Filter = filter
+
+	for _, sort := range req.Sort {
+		desc := sort.Order == "desc"
+		if sort.Order == "" {
+			desc = sort.Field == domain.SortScore
+		}
+		if sort.Field == domain.SortScore && !desc {
+			return params, fmt.Errorf("invalid sort: score is always best first and cannot be asc")
+		}
+		params.Sort = append(params.Sort, sortFields(sort.Field, desc)...)
+	}
+
+	if len(params.Facets) > 0 && params.FacetSize == 0 {
+		params.FacetSize = 10
+	}
+
+	return params, nil
+}
+
+func parseSearchFilter(c *gin.Context) (domain.SearchFilter, error) {
+	return newSearchFilter(c.Query("surah"), c.Query("juz"), c.Query("from"), c.Query("to"), c.Query("location"))
+}
+
+func newSearchFilter(surah, juz, from, to, location string) (domain.SearchFilter, error) {
+	var filter domain.SearchFilter
+
+	if surah != "" {
+		number, ok := service.LookupSurah(surah)
+		if !ok {
+			return filter, fmt.Errorf("invalid surah %q: use a number between 1 and 114 or a surah name", surah)
+		}
+		filter.Surah = number
+	}
+
+	if juz != "" {
+		number, err := strconv.Atoi(juz)
+		if err != nil || number < 1 || number > 30 {
+			return filter, fmt.Errorf("invalid juz %q: must be between 1 and 30", juz)
+		}
+		filter.Juz = number
+	}
+
+	if from != "" {
+		ref, ok := service.ParseReference(from)
+		if !ok {
+			return filter, fmt.Errorf("invalid from %q: expected a verse reference such as 2:1", from)
+		}
+		filter.From = &ref
+	}
+
+	if to != "" {
+		ref, ok := service.ParseReference(to)
+		if !ok {
+			return filter, fmt.Errorf("invalid to %q: expected a verse reference such as 2:100", to)
+		}
+		filter.To = &ref
+	}
+
+	if filter.From != nil && filter.To != nil {
+		if filter.From.Surah > filter.To.Surah ||
+			(filter.From.Surah == filter.To.Surah && filter.From.Ayah > filter.To.Ayah) {
+			return filter, fmt.Errorf("invalid range: from %s is after to %s", filter.From, filter.To)
+		}
+	}
+
+	if location != "" {
+		filter.Location = service.NormalizeLocation(location)
+		if filter.Location == "" {
+			return filter, fmt.Errorf("invalid location %q: must be %s or %s", location, domain.LocationMakkiyah, domain.LocationMadaniyah)
+		}
+	}
+
+	return filter, nil
+}
+
+func badSearchRequest(c *gin.Context, message string, page, limit int) {
+	c.JSON(http.StatusBadRequest, dto.SearchResponse{
+		Code:    http.StatusBadRequest,
+		Status:  "Bad Request",
+		Message: message,
+		Meta: dto.Meta{
+			Total:      0,
+			Page:       page,
+			Limit:      limit,
+			TotalPages: 0,
 		},
-		Data: ayahs,
 	})
 }
@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/anugrahsputra/go-quran-api/config"
+	"github.com/anugrahsputra/go-quran-api/internal/delivery/dto"
+	"github.com/anugrahsputra/go-quran-api/internal/domain"
+	"github.com/anugrahsputra/go-quran-api/internal/repository"
+	"github.com/anugrahsputra/go-quran-api/internal/service"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdvancedSearch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	t.Setenv("ADMIN_API_KEY", "secret")
+
+	mockRepo := new(MockQuranSearchRepository)
+	mockRepo.On("Search", mock.Anything).Return(&domain.IndexResults{}, nil)
+	mockRepo.On("SpellingSuggestions", mock.Anything, mock.Anything).Return([]string(nil), nil)
+	translationRepo, err := repository.NewTranslationRepository(&config.Config{})
+	require.NoError(t, err)
+	translationService := service.NewTranslationService(translationRepo)
+	h := NewQuranSearchHandler(service.NewQuranSearchService(nil, nil, mockRepo, nil, nil, translationService, nil, nil), translationService)
+
+	r := gin.Default()
+	r.POST("/search", h.AdvancedSearch)
+
+	tests := []struct {
+		name  string
+		body  string
+		admin bool
+		code  int
+	}{
+		{"valid", `{
+			"must": [{"text": "sabar", "field": "translation"}],
+			"must_not": [{"text": "riba"}],
+			"filter": {"surah": 2},
+			"sort": [{"field": "surah"}],
+			"limit": 5
+		}`, false, http.StatusOK},
+		{"admin scoring model", `{"query": "sabar", "scoring_model": "bm25", "boosts": {"ngram": 0.5}}`, true, http.StatusOK},
+		{"mushaf sort", `{"query": "sabar", "sort": [{"field": "mushaf", "order": "desc"}]}`, false, http.StatusOK},
+		{"ascending score", `{"query": "sabar", "sort": [{"field": "score", "order": "asc"}]}`, false, http.StatusBadRequest},
+		{"scoring model without admin token", `{"query": "sabar", "scoring_model": "bm25"}`, false, http.StatusForbidden},
+		{"unknown scoring model", `{"query": "sabar", "scoring_model": "dfr"}`, true, http.StatusBadRequest},
+		{"boosts without admin token", `{"query": "sabar", "boosts": {"tafsir": 2}}`, false, http.StatusForbidden},
+		{"explain without admin token", `{"query": "sabar", "explain": true}`, false, http.StatusForbidden},
+		{"limit out of range", `{"should": [{"text": "sabar"}], "limit": 500}`, false, http.StatusBadRequest},
+		{"unknown field", `{"must": [{"text": "sabar", "field": "arabic"}]}`, false, http.StatusBadRequest},
+		{"only excluded clauses", `{"must_not": [{"text": "riba"}]}`, false, http.StatusBadRequest},
+		{"invalid query syntax", `{"query": "\"sabar"}`, false, http.StatusBadRequest},
+		{"unknown translation", `{"query": "sabar", "lang": "fr"}`, false, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/search", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.admin {
+				req.Header.Set("X-Admin-Token", "secret")
+			}
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.code, w.Code, w.Body.String())
+		})
+	}
+
+	mockRepo.AssertNumberOfCalls(t, "Search", 3)
+	mockRepo.AssertCalled(t, "Search", mock.MatchedBy(func(params domain.SearchParams) bool {
+		return assert.ObjectsAreEqual([]domain.QueryClause{
+			{Field: domain.FieldTranslation, Text: "sabar", Occur: domain.OccurMust},
+			{Text: "riba", Occur: domain.OccurMustNot},
+		}, params.Clauses) &&
+			assert.ObjectsAreEqual([]domain.SortField{{Field: domain.SortSurahNumber}, {Field: domain.SortScore, Desc: true}}, params.Sort) &&
+			params.Filter.Surah == 2 && params.Limit == 5 && params.Translation == ""
+	}))
+	mockRepo.AssertCalled(t, "Search", mock.MatchedBy(func(params domain.SearchParams) bool {
+		return assert.ObjectsAreEqual([]domain.SortField{{Field: domain.SortSurah, Desc: true}}, params.Sort)
+	}))
+	mockRepo.AssertCalled(t, "Search", mock.MatchedBy(func(params domain.SearchParams) bool {
+		return params.ScoringModel == domain.ScoringBM25 &&
+			assert.ObjectsAreEqual(map[string]float64{domain.BoostNgram: 0.5}, params.Boosts)
+	}))
+}
+
+func TestSearchPinsReference(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	pinned := domain.SearchedAyah{SurahNumber: 2, AyahNumber: 255, Juz: 3, Location: domain.LocationMadaniyah}
+	fullText := []domain.IndexHit{
+		{SearchedAyah: domain.SearchedAyah{SurahNumber: 2, AyahNumber: 256}},
+		{SearchedAyah: domain.SearchedAyah{SurahNumber: 3, AyahNumber: 2}},
+	}
+
+	tests := []struct {
+		name      string
+		query     string
+		wantHits  []string
+		wantTotal int
+		// wantPinned is whether the full-text search leaves room for the
+		// pinned verse and excludes it.
+		wantPinned bool
+	}{
+		{"first page is trimmed to the limit", "q=2:255&limit=2", []string{"2:255", "2:256"}, 6, true},
+		{"later pages exclude the pinned verse", "q=2:255&limit=2&page=2", []string{"2:256", "3:2"}, 6, true},
+		{"verse outside the filter is not pinned", "q=2:255&limit=2&surah=3", []string{"2:256", "3:2"}, 5, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockQuranSearchRepository)
+			mockRepo.On("GetDocument", "2:255").Return(&pinned, nil)
+			mockRepo.On("Search", mock.Anything).Return(&domain.IndexResults{Hits: fullText, Total: 5}, nil)
+			h := NewQuranSearchHandler(service.NewQuranSearchService(nil, nil, mockRepo, nil, nil, nil, nil, nil), nil)
+
+			r := gin.Default()
+			r.GET("/search", h.Search)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/search?"+tt.query, nil)
+			r.ServeHTTP(w, req)
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+			var resp dto.SearchResponse
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			var ids []string
+			for _, hit := range resp.Data {
+				ids = append(ids, domain.VerseRef{Surah: hit.SurahNumber, Ayah: hit.AyahNumber}.String())
+			}
+			assert.Equal(t, tt.wantHits, ids)
+			assert.Equal(t, tt.wantTotal, resp.Meta.Total)
+			assert.Equal(t, (tt.wantTotal+1)/2, resp.Meta.TotalPages)
+
+			mockRepo.AssertCalled(t, "Search", mock.MatchedBy(func(params domain.SearchParams) bool {
+				if !tt.wantPinned {
+					return params.Pinned == 0 && len(params.Exclude) == 0
+				}
+				return params.Pinned == 1 && slices.Equal(params.Exclude, []string{"2:255"})
+			}))
+		})
+	}
+}
+
+func TestParseSort(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []domain.SortField
+	}{
+		{"relevance", []domain.SortField{{Field: domain.SortScore, Desc: true}}},
+		{"score", []domain.SortField{{Field: domain.SortScore, Desc: true}}},
+		{"mushaf", []domain.SortField{{Field: domain.SortSurah}}},
+		{"-mushaf", []domain.SortField{{Field: domain.SortSurah, Desc: true}}},
+		{"Surah", []domain.SortField{{Field: domain.SortSurahNumber}, {Field: domain.SortScore, Desc: true}}},
+		{"-surah", []domain.SortField{{Field: domain.SortSurahNumber, Desc: true}, {Field: domain.SortScore, Desc: true}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			sort, err := parseSort(tt.value)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, sort)
+		})
+	}
+
+	for _, value := range []string{"juz", "-score", "-relevance"} {
+		_, err := parseSort(value)
+		assert.Error(t, err, value)
+	}
+}
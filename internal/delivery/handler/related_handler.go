@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/anugrahsputra/go-quran-api/internal/delivery/dto"
+	"github.com/anugrahsputra/go-quran-api/internal/domain"
+	"github.com/anugrahsputra/go-quran-api/internal/service"
+	"github.com/anugrahsputra/go-quran-api/utils/helper"
+	"github.com/gin-gonic/gin"
+)
+
+// Related lists the verses most similar to the one named by ayah_id, which
+// is either an absolute ayah ID or a reference such as "2:255".
+func (h *QuranSearchHandler) Related(c *gin.Context) {
+	refStr := c.Param("ayah_id")
+	ref, ok := parseAyahRef(refStr)
+	if !ok {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  http.StatusBadRequest,
+			Message: fmt.Sprintf("invalid ayah %q: expected an ayah ID (1-6236) or a reference such as 2:255", refStr),
+		})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if limit < 1 || limit > 50 {
+		limit = 10
+	}
+
+	translation, err := h.selectedTranslation(c.Query("lang"), c.Query("translation"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+			Status:  http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	logger.Infof(
+		"HTTP %s %s | IP: %s | Params: ref=%s, limit=%d | UA: %s",
+		c.Request.Method,
+		c.Request.URL.Path,
+		c.ClientIP(),
+		ref,
+		limit,
+		c.Request.UserAgent(),
+	)
+
+	source, related, err := h.quranSearchService.Related(ref, limit, translation)
+	if err != nil {
+		logger.Errorf("Error finding verses related to %s: %s", ref, err)
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+			Status:  http.StatusInternalServerError,
+			Message: helper.SanitizeError(err),
+		})
+		return
+	}
+	if source == nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse{
+			Status:  http.StatusNotFound,
+			Message: fmt.Sprintf("ayah %s is not in the search index", ref),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.Response{
+		Status:  http.StatusOK,
+		Message: "success",
+		Data: dto.RelatedData{
+			Source:  *source,
+			Related: related,
+		},
+	})
+}
+
+// parseAyahRef reads an absolute ayah ID or a verse reference.
+func parseAyahRef(value string) (domain.VerseRef, bool) {
+	if number, err := strconv.Atoi(value); err == nil {
+		return service.VerseByNumber(number)
+	}
+	return service.ParseReference(value)
+}
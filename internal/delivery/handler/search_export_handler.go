@@ -0,0 +1,309 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/anugrahsputra/go-quran-api/internal/delivery/dto"
+	"github.com/anugrahsputra/go-quran-api/internal/domain"
+	"github.com/anugrahsputra/go-quran-api/internal/service"
+	"github.com/anugrahsputra/go-quran-api/utils/helper"
+	"github.com/anugrahsputra/go-quran-api/utils/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	exportCSV    = "csv"
+	exportNDJSON = "ndjson"
+)
+
+// exportFlushInterval is the number of rows written between flushes of a
+// streamed export.
+const exportFlushInterval = 100
+
+// exportWriteTimeout is how long writing the next rows of an export may
+// take. The deadline moves on with every flush, so a long export is not
+// cut off by the server WriteTimeout. The export route is also exempt from
+// the request timeout middleware.
+const exportWriteTimeout = 15 * time.Second
+
+// exportColumn is a column an export can select and the value it takes
+// from a hit.
+type exportColumn struct {
+	name  string
+	value func(domain.SearchHit) any
+}
+
+// exportColumns lists the columns an export can select.
+var exportColumns = []exportColumn{
+	{"surah_number", func(h domain.SearchHit) any { return h.SurahNumber }},
+	{"ayah_number", func(h domain.SearchHit) any { return h.AyahNumber }},
+	{"surah_name", func(h domain.SearchHit) any { return h.SurahName }},
+	{"text", func(h domain.SearchHit) any { return h.Text }},
+	{"latin", func(h domain.SearchHit) any { return h.Latin }},
+	{"translation", func(h domain.SearchHit) any { return h.Translation }},
+	{"matched_translation", func(h domain.SearchHit) any { return h.MatchedTranslation }},
+	{"topic", func(h domain.SearchHit) any { return h.Topic }},
+	{"juz", func(h domain.SearchHit) any { return h.Juz }},
+	{"page", func(h domain.SearchHit) any { return h.Page }},
+	{"location", func(h domain.SearchHit) any { return h.Location }},
+	{"kitabah", func(h domain.SearchHit) any { return h.Kitabah }},
+	{"tafsir", func(h domain.SearchHit) any { return h.Tafsir }},
+	{"wajiz", func(h domain.SearchHit) any { return h.Wajiz }},
+	{"sabab_nuzul", func(h domain.SearchHit) any { return h.SababNuzul }},
+	{"kosakata", func(h domain.SearchHit) any { return h.Kosakata }},
+	{"conclusion", func(h domain.SearchHit) any { return h.Conclusion }},
+}
+
+// defaultExportColumns are exported when no columns are selected.
+var defaultExportColumns = []string{"surah_number", "ayah_number", "surah_name", "text", "latin", "translation", "topic", "juz", "page", "location"}
+
+// tafsirExportColumns are only read from the index when selected.
+var tafsirExportColumns = []string{"tafsir", "wajiz", "sabab_nuzul", "kosakata", "conclusion"}
+
+// ExportSearch streams every hit of a search in mushaf order as CSV or
+// newline-delimited JSON. It takes the same query and filters as Search,
+// without paging.
+func (h *QuranSearchHandler) ExportSearch(c *gin.Context) {
+	query := c.Query("q")
+	if query == "" {
+		badExportRequest(c, "Query parameter 'q' is required")
+		return
+	}
+	if len(query) > 100 {
+		badExportRequest(c, "Search query too long (max 100 characters)")
+		return
+	}
+
+	format := strings.ToLower(c.Query("format"))
+	if format != exportCSV && format != exportNDJSON {
+		badExportRequest(c, fmt.Sprintf("invalid format %q: must be %s or %s", c.Query("format"), exportCSV, exportNDJSON))
+		return
+	}
+
+	columns := defaultExportColumns
+	if value := c.Query("columns"); value != "" {
+		var err error
+		columns, err = parseExportColumns(value)
+		if err != nil {
+			badExportRequest(c, err.Error())
+			return
+		}
+	}
+
+	params, err := parseSearchParams(c)
+	if err != nil {
+		badExportRequest(c, err.Error())
+		return
+	}
+	params.Translation, err = h.selectedTranslation(c.Query("lang"), c.Query("translation"))
+	if err != nil {
+		badExportRequest(c, err.Error())
+		return
+	}
+	if params.ScoringModel != "" && !middleware.IsAdmin(c) {
+		c.JSON(http.StatusForbidden, dto.ErrorResponse{
+			Status:  http.StatusForbidden,
+			Message: "scoring_model requires a valid X-Admin-Token",
+		})
+		return
+	}
+	params.Query = query
+	params.OmitTafsir = !slices.ContainsFunc(columns, func(column string) bool {
+		return slices.Contains(tafsirExportColumns, column)
+	})
+
+	writer := newExportWriter(c, format, columns)
+	count, err := h.quranSearchService.Export(params, writer.write)
+	if err == nil {
+		err = writer.close()
+	}
+	if err == nil {
+		return
+	}
+	if writer.started {
+		// The status has been sent, so the export can only be cut short.
+		logger.Errorf("Export of '%s' failed after %d rows: %s", query, count, err)
+		return
+	}
+
+	var syntaxErr *service.QuerySyntaxError
+	if errors.As(err, &syntaxErr) {
+		badExportRequest(c, syntaxErr.Error())
+		return
+	}
+	c.JSON(http.StatusInternalServerError, dto.ErrorResponse{
+		Status:  http.StatusInternalServerError,
+		Message: helper.SanitizeError(err),
+	})
+}
+
+// parseExportColumns reads a comma-separated list of export columns.
+func parseExportColumns(value string) ([]string, error) {
+	var columns []string
+	for _, column := range strings.Split(value, ",") {
+		column = strings.ToLower(strings.TrimSpace(column))
+		if !slices.ContainsFunc(exportColumns, func(c exportColumn) bool { return c.name == column }) {
+			return nil, fmt.Errorf("invalid column %q: must be one of %s", column, strings.Join(exportColumnNames(), ", "))
+		}
+		if !slices.Contains(columns, column) {
+			columns = append(columns, column)
+		}
+	}
+	return columns, nil
+}
+
+func exportColumnNames() []string {
+	names := make([]string, len(exportColumns))
+	for i, column := range exportColumns {
+		names[i] = column.name
+	}
+	return names
+}
+
+// exportWriter writes hits as rows of the selected columns. The response
+// headers are only sent with the first row, so that an export failing
+// before any hit is found can still answer with an error.
+type exportWriter struct {
+	c       *gin.Context
+	format  string
+	columns []string
+	values  []func(domain.SearchHit) any
+	csv     *csv.Writer
+	rows    int
+	started bool
+}
+
+func newExportWriter(c *gin.Context, format string, columns []string) *exportWriter {
+	w := &exportWriter{c: c, format: format, columns: columns}
+	for _, name := range columns {
+		for _, column := range exportColumns {
+			if column.name == name {
+				w.values = append(w.values, column.value)
+			}
+		}
+	}
+	return w
+}
+
+func (w *exportWriter) start() error {
+	w.started = true
+	if err := w.extendDeadline(); err != nil {
+		return err
+	}
+	contentType := "text/csv; charset=utf-8"
+	if w.format == exportNDJSON {
+		contentType = "application/x-ndjson"
+	}
+	w.c.Header("Content-Type", contentType)
+	w.c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="quran-search.%s"`, w.format))
+	w.c.Status(http.StatusOK)
+
+	if w.format != exportCSV {
+		return nil
+	}
+	w.csv = csv.NewWriter(w.c.Writer)
+	return w.csv.Write(w.columns)
+}
+
+func (w *exportWriter) write(hit domain.SearchHit) error {
+	if err := w.c.Request.Context().Err(); err != nil {
+		return err
+	}
+	if !w.started {
+		if err := w.start(); err != nil {
+			return err
+		}
+	}
+
+	var err error
+	if w.format == exportCSV {
+		err = w.writeCSV(hit)
+	} else {
+		err = w.writeNDJSON(hit)
+	}
+	if err != nil {
+		return err
+	}
+
+	w.rows++
+	if w.rows%exportFlushInterval == 0 {
+		return w.flush()
+	}
+	return nil
+}
+
+func (w *exportWriter) writeCSV(hit domain.SearchHit) error {
+	record := make([]string, len(w.values))
+	for i, value := range w.values {
+		record[i] = fmt.Sprint(value(hit))
+	}
+	return w.csv.Write(record)
+}
+
+// writeNDJSON writes one JSON object per line, keeping the column order.
+func (w *exportWriter) writeNDJSON(hit domain.SearchHit) error {
+	var line strings.Builder
+	line.WriteByte('{')
+	for i, value := range w.values {
+		if i > 0 {
+			line.WriteByte(',')
+		}
+		key, _ := json.Marshal(w.columns[i])
+		data, err := json.Marshal(value(hit))
+		if err != nil {
+			return err
+		}
+		line.Write(key)
+		line.WriteByte(':')
+		line.Write(data)
+	}
+	line.WriteString("}\n")
+	_, err := w.c.Writer.WriteString(line.String())
+	return err
+}
+
+func (w *exportWriter) flush() error {
+	if w.csv != nil {
+		w.csv.Flush()
+		if err := w.csv.Error(); err != nil {
+			return err
+		}
+	}
+	w.c.Writer.Flush()
+	return w.extendDeadline()
+}
+
+// extendDeadline gives the next rows exportWriteTimeout to be written.
+// Writers without deadlines, such as test recorders, are left alone.
+func (w *exportWriter) extendDeadline() error {
+	err := http.NewResponseController(w.c.Writer).SetWriteDeadline(time.Now().Add(exportWriteTimeout))
+	if errors.Is(err, http.ErrNotSupported) {
+		return nil
+	}
+	return err
+}
+
+// close finishes the export, sending the headers alone when nothing
+// matched.
+func (w *exportWriter) close() error {
+	if !w.started {
+		if err := w.start(); err != nil {
+			return err
+		}
+	}
+	return w.flush()
+}
+
+func badExportRequest(c *gin.Context, message string) {
+	c.JSON(http.StatusBadRequest, dto.ErrorResponse{
+		Status:  http.StatusBadRequest,
+		Message: message,
+	})
+}
@@ -0,0 +1,181 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/anugrahsputra/go-quran-api/internal/domain"
+	"github.com/anugrahsputra/go-quran-api/internal/service"
+	"github.com/anugrahsputra/go-quran-api/utils/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func exportResult(surah int, ayahs ...int) *domain.IndexResults {
+	result := &domain.IndexResults{Total: len(ayahs)}
+	for _, ayah := range ayahs {
+		result.Hits = append(result.Hits, domain.IndexHit{
+			SearchedAyah: domain.SearchedAyah{
+				SurahNumber: surah,
+				AyahNumber:  ayah,
+				Translation: "sabar, \"dan\" shalat",
+				Juz:         1,
+			},
+			SortValues: []string{strconv.Itoa(surah), strconv.Itoa(ayah)},
+		})
+	}
+	return result
+}
+
+func TestExportSearch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	newRouter := func(repo *MockQuranSearchRepository) *gin.Engine {
+		h := NewQuranSearchHandler(service.NewQuranSearchService(nil, nil, repo, nil, nil, nil, nil, nil), nil)
+		r := gin.New()
+		r.GET("/search/export", h.ExportSearch)
+		return r
+	}
+
+	t.Run("csv in batches", func(t *testing.T) {
+		full := make([]int, 100)
+		for i := range full {
+			full[i] = i + 1
+		}
+		mockRepo := new(MockQuranSearchRepository)
+		mockRepo.On("Search", mock.Anything).Return(exportResult(2, full...), nil).Once()
+		mockRepo.On("Search", mock.Anything).Return(exportResult(2, 101), nil).Once()
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/search/export?q=sabar&format=csv&columns=surah_number,ayah_number,translation&highlight=html", nil)
+		newRouter(mockRepo).ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Equal(t, `attachment; filename="quran-search.csv"`, w.Header().Get("Content-Disposition"))
+
+		lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+		require.Len(t, lines, 102)
+		assert.Equal(t, "surah_number,ayah_number,translation", lines[0])
+		assert.Equal(t, `2,1,"sabar, ""dan"" shalat"`, lines[1])
+		assert.Equal(t, `2,101,"sabar, ""dan"" shalat"`, lines[101])
+
+		mockRepo.AssertNumberOfCalls(t, "Search", 2)
+		mockRepo.AssertCalled(t, "Search", mock.MatchedBy(func(params domain.SearchParams) bool {
+			return len(params.SearchAfter) == 0 &&
+				assert.ObjectsAreEqual([]domain.SortField{{Field: domain.SortSurah}}, params.Sort) &&
+				params.Limit == 100 && params.Highlight == "" && params.OmitTafsir
+		}))
+		mockRepo.AssertCalled(t, "Search", mock.MatchedBy(func(params domain.SearchParams) bool {
+			return assert.ObjectsAreEqual([]string{"2", "100"}, params.SearchAfter)
+		}))
+	})
+
+	t.Run("ndjson keeps column order", func(t *testing.T) {
+		mockRepo := new(MockQuranSearchRepository)
+		mockRepo.On("Search", mock.Anything).Return(exportResult(2, 153), nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/search/export?q=sabar&format=ndjson&columns=translation,juz,tafsir", nil)
+		newRouter(mockRepo).ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+		assert.Equal(t, `{"translation":"sabar, \"dan\" shalat","juz":1,"tafsir":""}`+"\n", w.Body.String())
+		mockRepo.AssertCalled(t, "Search", mock.MatchedBy(func(params domain.SearchParams) bool {
+			return !params.OmitTafsir
+		}))
+	})
+
+	t.Run("no hits", func(t *testing.T) {
+		mockRepo := new(MockQuranSearchRepository)
+		mockRepo.On("Search", mock.Anything).Return(&domain.IndexResults{}, nil)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/search/export?q=sabar&format=csv&columns=juz", nil)
+		newRouter(mockRepo).ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "juz\n", w.Body.String())
+	})
+
+	t.Run("outlasts the server write timeout", func(t *testing.T) {
+		full := make([]int, 100)
+		for i := range full {
+			full[i] = i + 1
+		}
+		mockRepo := new(MockQuranSearchRepository)
+		mockRepo.On("Search", mock.Anything).Return(exportResult(2, full...), nil).After(150 * time.Millisecond).Times(3)
+		mockRepo.On("Search", mock.Anything).Return(exportResult(3, 1), nil).Once()
+
+		server := httptest.NewUnstartedServer(newRouter(mockRepo))
+		server.Config.WriteTimeout = 200 * time.Millisecond
+		server.Start()
+		defer server.Close()
+
+		resp, err := http.Get(server.URL + "/search/export?q=sabar&format=csv&columns=surah_number,ayah_number")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+
+		lines := strings.Split(strings.TrimSpace(string(body)), "\n")
+		require.Len(t, lines, 302)
+		assert.Equal(t, "3,1", lines[301])
+	})
+
+	t.Run("outlasts the request timeout", func(t *testing.T) {
+		full := make([]int, 100)
+		for i := range full {
+			full[i] = i + 1
+		}
+		mockRepo := new(MockQuranSearchRepository)
+		mockRepo.On("Search", mock.Anything).Return(exportResult(2, full...), nil).After(150 * time.Millisecond).Times(3)
+		mockRepo.On("Search", mock.Anything).Return(exportResult(3, 1), nil).Once()
+
+		h := NewQuranSearchHandler(service.NewQuranSearchService(nil, nil, mockRepo, nil, nil, nil, nil, nil), nil)
+		r := gin.New()
+		r.Use(middleware.Timeout(200*time.Millisecond, "/search/export"))
+		r.GET("/search/export", h.ExportSearch)
+
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/search/export?q=sabar&format=csv&columns=surah_number,ayah_number", nil)
+		r.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+		require.Len(t, lines, 302)
+		assert.Equal(t, "3,1", lines[301])
+	})
+
+	invalid := []struct {
+		name  string
+		query string
+		code  int
+	}{
+		{"missing format", "q=sabar", http.StatusBadRequest},
+		{"unknown format", "q=sabar&format=xml", http.StatusBadRequest},
+		{"unknown column", "q=sabar&format=csv&columns=arabic", http.StatusBadRequest},
+		{"invalid query syntax", "q=%22sabar&format=csv", http.StatusBadRequest},
+		{"scoring model without admin token", "q=sabar&format=csv&scoring_model=bm25", http.StatusForbidden},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockQuranSearchRepository)
+
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/search/export?"+tt.query, nil)
+			newRouter(mockRepo).ServeHTTP(w, req)
+
+			assert.Equal(t, tt.code, w.Code, w.Body.String())
+			mockRepo.AssertNotCalled(t, "Search", mock.Anything)
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/anugrahsputra/go-quran-api/internal/delivery/dto"
+	"github.com/anugrahsputra/go-quran-api/internal/domain"
+	"github.com/anugrahsputra/go-quran-api/internal/service"
+	"github.com/anugrahsputra/go-quran-api/utils/helper"
+	"github.com/gin-gonic/gin"
+)
+
+// SearchTafsir searches tafsir paragraphs, returning each matching
+// paragraph with the verse it explains.
+func (h *QuranSearchHandler) SearchTafsir(c *gin.Context) {
+	query := strings.TrimSpace(c.Query("q"))
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 10
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	if query == "" {
+		badSearchRequest(c, "Query parameter 'q' is required", page, limit)
+		return
+	}
+	if len(query) > 100 {
+		badSearchRequest(c, "Search query too long (max 100 characters)", page, limit)
+		return
+	}
+
+	filter, err := parseSearchFilter(c)
+	if err != nil {
+		badSearchRequest(c, err.Error(), page, limit)
+		return
+	}
+
+	highlight := c.Query("highlight")
+	if highlight != "" && highlight != domain.HighlightHTML && highlight != domain.HighlightPlain {
+		badSearchRequest(c, fmt.Sprintf("invalid highlight %q: must be %s or %s", highlight, domain.HighlightHTML, domain.HighlightPlain), page, limit)
+		return
+	}
+
+	params := domain.SearchParams{
+		Query:     query,
+		Page:      page,
+		Limit:     limit,
+		Filter:    filter,
+		Highlight: highlight,
+	}
+
+	results, err := h.quranSearchService.SearchTafsir(params)
+	var syntaxErr *service.QuerySyntaxError
+	if errors.As(err, &syntaxErr) {
+		badSearchRequest(c, syntaxErr.Error(), page, limit)
+		return
+	}
+	if err != nil {
+		logger.Errorf("Error searching tafsir: %s", err)
+		c.JSON(http.StatusInternalServerError, dto.TafsirSearchResponse{
+			Code:    http.StatusInternalServerError,
+			Status:  "Internal Server Error",
+			Message: helper.SanitizeError(err),
+			Meta: dto.Meta{
+				Page:  page,
+				Limit: limit,
+			},
+		})
+		return
+	}
+
+	totalPages := (results.Total + limit - 1) / limit
+
+	c.JSON(http.StatusOK, dto.TafsirSearchResponse{
+		Code:    http.StatusOK,
+		Status:  "OK",
+		Message: "Success",
+		Meta: dto.Meta{
+			Total:      results.Total,
+			Page:       page,
+			Limit:      limit,
+			TotalPages: totalPages,
+		},
+		Data: results.Hits,
+	})
+}
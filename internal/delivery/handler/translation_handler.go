@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/anugrahsputra/go-quran-api/internal/delivery/dto"
+	"github.com/anugrahsputra/go-quran-api/internal/domain"
+	"github.com/anugrahsputra/go-quran-api/internal/service"
+	"github.com/gin-gonic/gin"
+)
+
+type TranslationHandler struct {
+	translationService service.ITranslationService
+}
+
+func NewTranslationHandler(ts service.ITranslationService) *TranslationHandler {
+	return &TranslationHandler{
+		translationService: ts,
+	}
+}
+
+func (h *TranslationHandler) GetListTranslation(c *gin.Context) {
+	c.JSON(http.StatusOK, dto.Response{
+		Status:  http.StatusOK,
+		Message: "success",
+		Data:    h.translationService.List(),
+	})
+}
+
+// resolveTranslation reads the optional lang and translation query
+// parameters.
+func resolveTranslation(c *gin.Context, ts service.ITranslationService) (domain.TranslationInfo, error) {
+	return ts.Resolve(c.Query("lang"), c.Query("translation"))
+}
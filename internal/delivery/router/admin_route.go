@@ -6,6 +6,16 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func AdminRoute(g *gin.RouterGroup, h *handler.AdminHandler, rl *middleware.RateLimiter) {
+// AdminRoute keeps POST /reindex where clients already call it and puts the
+// other admin operations under /admin, apart from the public routes and
+// their rate limit.
+func AdminRoute(g *gin.RouterGroup, h *handler.AdminHandler, rl, adminRL *middleware.RateLimiter) {
 	g.POST("/reindex", middleware.AdminAuth(), rl.Middleware(), h.Reindex)
+
+	adminGroup := g.Group("/admin", middleware.AdminAuth(), adminRL.Middleware())
+	{
+		adminGroup.POST("/synonyms/reload", h.ReloadSynonyms)
+		adminGroup.GET("/search/cache", h.SearchCacheStats)
+		adminGroup.GET("/search/analytics", h.SearchAnalytics)
+	}
 }
@@ -0,0 +1,14 @@
+package router
+
+import (
+	"github.com/anugrahsputra/go-quran-api/internal/delivery/handler"
+	"github.com/anugrahsputra/go-quran-api/utils/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+func CompareRoute(r *gin.RouterGroup, h *handler.CompareHandler, rl *middleware.RateLimiter) {
+	compareGroup := r.Group("/compare", rl.Middleware())
+	{
+		compareGroup.GET("/:ref", h.Compare)
+	}
+}
@@ -24,10 +24,11 @@ func wireApiRootRoute(rc *redis.Client) *handler.ApiRootHandler {
 	return handler.NewApiRootHandler(apiRootService)
 }
 
-func wireSurahRoutes(surahRepo domain.SurahRepository, ayahRepo domain.AyahRepository, rc *redis.Client) (*handler.SurahHandler, *handler.DetailSurahHandler, *handler.DetailAyahHandler) {
-	surahService := service.NewSurahService(surahRepo, rc)
-	ayahService := service.NewAyahService(ayahRepo, rc)
-	return handler.NewSurahHandler(surahService), handler.NewDetailSurahHandler(surahService), handler.NewDetailAyahHandler(ayahService)
+func wireSurahRoutes(surahRepo domain.SurahRepository, ayahRepo domain.AyahRepository, rc *redis.Client, ts service.ITranslationService) (*handler.SurahHandler, *handler.DetailSurahHandler, *handler.DetailAyahHandler, *handler.CompareHandler) {
+	surahService := service.NewSurahService(surahRepo, rc, ts)
+	ayahService := service.NewAyahService(ayahRepo, rc, ts)
+	compareService := service.NewCompareService(surahService, ts)
+	return handler.NewSurahHandler(surahService), handler.NewDetailSurahHandler(surahService, ts), handler.NewDetailAyahHandler(ayahService, ts), handler.NewCompareHandler(compareService, ts)
 }
 
 func wirePrayerTime(cfg *config.Config) *handler.PrayerTimeHandler {
@@ -36,17 +37,18 @@ func wirePrayerTime(cfg *config.Config) *handler.PrayerTimeHandler {
 	return handler.NewPrayerTimeHandler(prayerTimeService)
 }
 
-func wireQuranSearch(searchService service.IQuranSearchService) (*handler.QuranSearchHandler, *handler.AdminHandler) {
-	return handler.NewQuranSearchHandler(searchService), handler.NewAdminHandler(searchService)
+func wireQuranSearch(searchService service.IQuranSearchService, ts service.ITranslationService) (*handler.QuranSearchHandler, *handler.AdminHandler) {
+	return handler.NewQuranSearchHandler(searchService, ts), handler.NewAdminHandler(searchService)
 }
 
 type RouterDeps struct {
-	Cfg           *config.Config
-	SurahRepo     domain.SurahRepository
-	AyahRepo      domain.AyahRepository
-	SearchRepo    domain.QuranSearchRepository
-	SearchService service.IQuranSearchService
-	RedisClient   *redis.Client
+	Cfg                *config.Config
+	SurahRepo          domain.SurahRepository
+	AyahRepo           domain.AyahRepository
+	SearchRepo         domain.QuranSearchRepository
+	SearchService      service.IQuranSearchService
+	TranslationService service.ITranslationService
+	RedisClient        *redis.Client
 }
 
 func SetupRoute(deps RouterDeps) *gin.Engine {
@@ -67,7 +69,8 @@ func SetupRoute(deps RouterDeps) *gin.Engine {
 	route.Use(middleware.Recovery())
 	route.Use(middleware.RequestID())
 	route.Use(middleware.SecurityHeaders())
-	route.Use(middleware.Timeout(30 * time.Second))
+	// Exports stream every hit of a search and keep their own write deadline.
+	route.Use(middleware.Timeout(30*time.Second, "/api/v1/search/export"))
 	route.Use(middleware.BodySizeLimit(1 << 20))
 
 	healthHandler := wireHealth(deps.SearchRepo)
@@ -81,17 +84,24 @@ func SetupRoute(deps RouterDeps) *gin.Engine {
 
 	apiV1 := api.Group("/v1")
 
-	surahHandler, detailSurahHandler, detailAyahHandler := wireSurahRoutes(deps.SurahRepo, deps.AyahRepo, deps.RedisClient)
+	surahHandler, detailSurahHandler, detailAyahHandler, compareHandler := wireSurahRoutes(deps.SurahRepo, deps.AyahRepo, deps.RedisClient, deps.TranslationService)
 	SurahRoute(apiV1, surahHandler, rateLimiter)
 	DetailSurahRoute(apiV1, detailSurahHandler, rateLimiter)
 	DetailAyahRoute(apiV1, detailAyahHandler, rateLimiter)
+	CompareRoute(apiV1, compareHandler, rateLimiter)
 
 	prayerTimeHandler := wirePrayerTime(deps.Cfg)
 	PrayerTimeRoute(apiV1, prayerTimeHandler, rateLimiter)
 
-	searchHandler, adminHandler := wireQuranSearch(deps.SearchService)
-	NewQuranSearchRoute(apiV1, searchHandler, rateLimiter)
-	AdminRoute(apiV1, adminHandler, rateLimiter)
+	translationHandler := handler.NewTranslationHandler(deps.TranslationService)
+	TranslationRoute(apiV1, translationHandler, rateLimiter)
+
+	searchHandler, adminHandler := wireQuranSearch(deps.SearchService, deps.TranslationService)
+	// An export reads every hit of a search, so far fewer are allowed.
+	exportRateLimiter := middleware.NewRateLimiter(deps.RedisClient, "ratelimit:quran-api:export", 0.1, 6)
+	NewQuranSearchRoute(apiV1, searchHandler, rateLimiter, exportRateLimiter)
+	adminRateLimiter := middleware.NewRateLimiter(deps.RedisClient, "ratelimit:quran-api:admin", 1.0, 30)
+	AdminRoute(apiV1, adminHandler, rateLimiter, adminRateLimiter)
 
 	return route
 }
@@ -6,6 +6,11 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func NewQuranSearchRoute(g *gin.RouterGroup, h *handler.QuranSearchHandler, rl *middleware.RateLimiter) {
+func NewQuranSearchRoute(g *gin.RouterGroup, h *handler.QuranSearchHandler, rl, exportRL *middleware.RateLimiter) {
 	g.GET("/search", rl.Middleware(), h.Search)
+	g.POST("/search", rl.Middleware(), h.AdvancedSearch)
+	g.GET("/search/export", exportRL.Middleware(), h.ExportSearch)
+	g.GET("/search/suggest", rl.Middleware(), h.Suggest)
+	g.GET("/search/tafsir", rl.Middleware(), h.SearchTafsir)
+	g.GET("/ayah/:ayah_id/related", rl.Middleware(), h.Related)
 }
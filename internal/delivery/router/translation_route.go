@@ -0,0 +1,14 @@
+package router
+
+import (
+	"github.com/anugrahsputra/go-quran-api/internal/delivery/handler"
+	"github.com/anugrahsputra/go-quran-api/utils/middleware"
+	"github.com/gin-gonic/gin"
+)
+
+func TranslationRoute(r *gin.RouterGroup, h *handler.TranslationHandler, rl *middleware.RateLimiter) {
+	translationGroup := r.Group("/translations", rl.Middleware())
+	{
+		translationGroup.GET("/", h.GetListTranslation)
+	}
+}
@@ -1,6 +1,90 @@
 package domain
 
-import "github.com/blevesearch/bleve/v2"
+import "strings"
+
+const (
+	MatchTypeReference = "reference"
+	MatchTypeFullText  = "fulltext"
+)
+
+const (
+	HighlightHTML  = "html"
+	HighlightPlain = "plain"
+)
+
+const (
+	CompletionSurah = "surah"
+	CompletionTerm  = "term"
+)
+
+const (
+	FacetSurah = "surah"
+	FacetJuz   = "juz"
+	FacetTopic = "topic"
+)
+
+const (
+	FieldTranslation = "translation"
+	FieldLatin       = "latin"
+	FieldTafsir      = "tafsir"
+	FieldTopic       = "topic"
+	FieldWajiz       = "wajiz"
+	FieldSababNuzul  = "sabab_nuzul"
+	FieldKosakata    = "kosakata"
+	FieldConclusion  = "conclusion"
+	FieldKitabah     = "kitabah"
+	// FieldSurah matches a surah by name or number.
+	FieldSurah = "surah"
+	// FieldJuz and FieldPage match a number or a range such as "1-3".
+	FieldJuz  = "juz"
+	FieldPage = "page"
+)
+
+// SearchFields lists the fields a query can be scoped to.
+var SearchFields = []string{
+	FieldTranslation, FieldLatin, FieldTafsir, FieldTopic, FieldWajiz, FieldSababNuzul,
+	FieldKosakata, FieldConclusion, FieldKitabah, FieldSurah, FieldJuz, FieldPage,
+}
+
+// DefaultSearchFields are searched when a query names no fields, in the
+// order they are searched.
+var DefaultSearchFields = []string{
+	FieldTranslation, FieldLatin, FieldTafsir, FieldTopic, FieldWajiz, FieldSababNuzul,
+	FieldKosakata, FieldConclusion, FieldKitabah,
+}
+
+// NumericSearchFields only match numbers and number ranges.
+var NumericSearchFields = []string{FieldJuz, FieldPage}
+
+// BoostNgram weights partial-word matches in SearchParams.Boosts.
+const BoostNgram = "ngram"
+
+// Scoring models supported by the search index.
+const (
+	ScoringTFIDF = "tfidf"
+	ScoringBM25  = "bm25"
+)
+
+const (
+	OccurShould  = "should"
+	OccurMust    = "must"
+	OccurMustNot = "must_not"
+)
+
+const (
+	SortScore = "score"
+	SortSurah = "surah"
+	SortJuz   = "juz"
+	SortPage  = "page"
+	// SortSurahNumber orders by surah only, leaving the order within a
+	// surah to the next sort field.
+	SortSurahNumber = "surah_number"
+)
+
+const (
+	LocationMakkiyah  = "Makkiyah"
+	LocationMadaniyah = "Madaniyah"
+)
 
 type SearchedAyah struct {
 	SurahNumber int    `json:"surah_number"`
@@ -9,12 +93,260 @@ type SearchedAyah struct {
 	Latin       string `json:"latin"`
 	Translation string `json:"translation"`
 	Tafsir      string `json:"tafsir"`
-	Topic       string `json:"topic"`
+	// Wajiz, SababNuzul, Kosakata and Conclusion are the other tafsir
+	// sections, omitted with Tafsir.
+	Wajiz      string `json:"wajiz,omitempty"`
+	SababNuzul string `json:"sabab_nuzul,omitempty"`
+	Kosakata   string `json:"kosakata,omitempty"`
+	Conclusion string `json:"conclusion,omitempty"`
+	Kitabah    string `json:"kitabah,omitempty"`
+	SurahName  string `json:"surah_name,omitempty"`
+	Topic      string `json:"topic"`
+	Juz        int    `json:"juz"`
+	Page       int    `json:"page"`
+	Location   string `json:"location"`
+	// Translations holds the verse in each loaded translation other than
+	// DefaultTranslation, keyed by translation ID. It is only used for
+	// indexing.
+	Translations map[string]string `json:"-"`
+}
+
+type SearchHit struct {
+	SearchedAyah
+	MatchType string `json:"match_type"`
+	// MatchedTranslation is the ID of the translation returned as
+	// Translation.
+	MatchedTranslation string              `json:"matched_translation,omitempty"`
+	Highlights         map[string][]string `json:"highlights,omitempty"`
+	// Explanation is only set when SearchParams.Explain is.
+	Explanation *ScoreExplanation `json:"explanation,omitempty"`
+}
+
+// ScoreExplanation breaks the score of a hit down for debugging relevance.
+type ScoreExplanation struct {
+	Score float64 `json:"score"`
+	// Fields sums the matched terms per index field, highest first, with
+	// number terms decoded and constant-scored clauses under
+	// "ConstantScore". Term scores include the coordination factors of the
+	// clauses above them, so they add up to Score.
+	Fields []FieldContribution `json:"fields"`
+	// Tree is the full explanation computed by the search engine.
+	Tree any `json:"tree"`
+}
+
+type FieldContribution struct {
+	Field string             `json:"field"`
+	Score float64            `json:"score"`
+	Terms []TermContribution `json:"terms"`
+}
+
+type TermContribution struct {
+	Term  string  `json:"term"`
+	Boost float64 `json:"boost"`
+	Score float64 `json:"score"`
+}
+
+// RelatedVerse is a verse similar to another one, with what they share.
+type RelatedVerse struct {
+	SearchedAyah
+	Score float64 `json:"score"`
+	// SharedTopic is set when both verses have the same topic.
+	SharedTopic string `json:"shared_topic,omitempty"`
+	// SharedTerms are the stemmed terms behind the match, most frequent
+	// first.
+	SharedTerms []string `json:"shared_terms"`
+}
+
+type FacetCount struct {
+	Value string `json:"value"`
+	Name  string `json:"name,omitempty"`
+	Count int    `json:"count"`
+}
+
+type SearchResults struct {
+	Hits  []SearchHit
+	Total int
+	// QueryLanguage is the given or detected query language, if any.
+	QueryLanguage string
+	Facets        map[string][]FacetCount
+	Suggestions   []string
+	// QueryTree is the query sent to the index, set when
+	// SearchParams.Explain is.
+	QueryTree any
+	// NextCursor continues after the last hit when there may be more.
+	NextCursor string
+}
+
+// IndexHit is a verse found by a QuranSearchRepository.
+type IndexHit struct {
+	SearchedAyah
+	Score float64
+	// MatchedTranslation is the ID of the translation returned as
+	// Translation.
+	MatchedTranslation string
+	// Highlights holds the snippets of each matched field, keyed like
+	// SearchFields.
+	Highlights map[string][]string
+	// Explanation is only set when SearchParams.Explain is.
+	Explanation *ScoreExplanation
+	// SortValues are the sort keys of the hit, which continue a search
+	// after it when passed as SearchParams.SearchAfter.
+	SortValues []string
+	// MatchedTerms counts the occurrences of each matched term. It is only
+	// set by QuranSearchRepository.Related.
+	MatchedTerms map[string]int
+}
+
+// IndexResults is a page of hits from a QuranSearchRepository.
+type IndexResults struct {
+	Hits  []IndexHit
+	Total int
+	// Facets counts the matching verses per value. Surah facets are left
+	// unnamed.
+	Facets map[string][]FacetCount
+	// Query is the query run by the index, set when SearchParams.Explain
+	// is.
+	Query any
+}
+
+type Completion struct {
+	Text        string `json:"text"`
+	Type        string `json:"type"`
+	Count       int    `json:"count,omitempty"`
+	SurahNumber int    `json:"surah_number,omitempty"`
+}
+
+type SearchFilter struct {
+	Surah    int
+	Juz      int
+	From     *VerseRef
+	To       *VerseRef
+	Location string
+}
+
+// Matches reports whether the filter lets ayah through.
+func (f SearchFilter) Matches(ayah SearchedAyah) bool {
+	ref := VerseRef{Surah: ayah.SurahNumber, Ayah: ayah.AyahNumber}
+	if f.Surah > 0 && ayah.SurahNumber != f.Surah {
+		return false
+	}
+	if f.Juz > 0 && ayah.Juz != f.Juz {
+		return false
+	}
+	if f.Location != "" && !strings.EqualFold(ayah.Location, f.Location) {
+		return false
+	}
+	if f.From != nil && ref.Compare(*f.From) < 0 {
+		return false
+	}
+	if f.To != nil && ref.Compare(*f.To) > 0 {
+		return false
+	}
+	return true
+}
+
+// QueryClause is one term or phrase of a parsed search query.
+type QueryClause struct {
+	// Field scopes the clause to one of SearchFields. When empty the clause
+	// searches every field selected by SearchParams.Fields.
+	Field  string
+	Text   string
+	Phrase bool
+	Occur  string
+	// Boost multiplies the field boosts for this clause. Zero means 1.
+	Boost float64
+	// Synonyms are alternative spellings matched like Text at a lower
+	// weight. Multi-word synonyms are matched as phrases.
+	Synonyms []string
+}
+
+type SortField struct {
+	// Field is one of the Sort* constants. SortSurah orders by surah and
+	// then ayah number.
+	Field string
+	Desc  bool
+}
+
+type SearchParams struct {
+	Query string
+	// Clauses are searched together with the clauses parsed from Query.
+	Clauses []QueryClause
+	Fields  []string
+	// Boosts overrides the default weight of individual SearchFields and
+	// of partial-word matches under BoostNgram.
+	Boosts map[string]float64
+	// ScoringModel overrides the configured scoring model when set.
+	ScoringModel string
+	// Explain returns how each hit was scored and the query tree.
+	Explain bool
+	// Cursor is a SearchResults.NextCursor to continue from instead of
+	// Page. The service decodes it into SearchAfter.
+	Cursor string
+	// SearchAfter holds the sort values of the hit to continue after.
+	SearchAfter []string
+	// Sort orders the hits; relevance order is used when empty.
+	Sort []SortField
+	// Translation selects the translation searched and returned as
+	// Translation. Empty means DefaultTranslation, or every translation in
+	// TranslationBoosts when that is set.
+	Translation string
+	// TranslationBoosts weights each searched translation by ID. Hits
+	// return the best matching one as Translation.
+	TranslationBoosts map[string]float64
+	// QueryLanguage is the language of the query, detected when empty.
+	QueryLanguage string
+	Page          int
+	Limit         int
+	Filter        SearchFilter
+	Highlight     string
+	OmitTafsir    bool
+	Facets        []string
+	FacetSize     int
+	// Fuzziness overrides the configured edit distance when set.
+	Fuzziness *int
+	// Exclude lists the IDs of verses left out of the hits, such as a
+	// reference pinned in front of them.
+	Exclude []string
+	// Pinned is the number of hits shown in front of the first page. The
+	// first page holds that many fewer search hits and later pages shift
+	// back by as many.
+	Pinned int
+}
+
+// Window returns the offset and number of the search hits to return. Page
+// and Limit default to 1 and 10 and Limit is capped at 100. A search
+// continuing after SearchAfter starts at offset 0.
+func (p SearchParams) Window() (from, size int) {
+	page, limit := max(p.Page, 1), p.Limit
+	if limit < 1 {
+		limit = 10
+	}
+	limit = min(limit, 100)
+	if len(p.SearchAfter) > 0 {
+		return 0, limit
+	}
+	if page == 1 {
+		return 0, max(limit-p.Pinned, 0)
+	}
+	return (page-1)*limit - p.Pinned, limit
 }
 
 type QuranSearchRepository interface {
 	Index(ayahs []SearchedAyah) error
-	Search(q string, page, limit int) (*bleve.SearchResult, error)
+	// Rebuild directs Index to a new, empty index while searches keep
+	// using the current one. The returned function replaces the current
+	// index with the new one when keep is true and discards it otherwise.
+	Rebuild() (finish func(keep bool) error, err error)
+	Search(params SearchParams) (*IndexResults, error)
+	SpellingSuggestions(q string, limit int) ([]string, error)
+	Complete(prefix string, limit int) ([]Completion, error)
+	// GetDocument returns the verse id, or nil when it is not indexed.
+	GetDocument(id string) (*SearchedAyah, error)
+	// Related finds the verses most similar to the verse id, or returns
+	// nil when it is not indexed.
+	Related(id string, limit int) (*IndexResults, error)
 	GetDocCount() (uint64, error)
 	IsHealthy() bool
+	// Close releases the index, discarding a rebuild that is still running.
+	Close() error
 }
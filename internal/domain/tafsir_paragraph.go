@@ -0,0 +1,36 @@
+package domain
+
+// TafsirParagraph is one paragraph of an ayah's Tahlili tafsir, indexed on
+// its own so that a search returns the passage that matched.
+type TafsirParagraph struct {
+	SurahNumber int `json:"surah_number"`
+	AyahNumber  int `json:"ayah_number"`
+	// Paragraph is the 1-based position of the paragraph in the tafsir.
+	Paragraph int    `json:"paragraph"`
+	Tafsir    string `json:"tafsir"`
+	// Text and Translation are the verse the paragraph explains.
+	Text        string `json:"text"`
+	Translation string `json:"translation"`
+	Juz         int    `json:"juz"`
+	Location    string `json:"location"`
+}
+
+type TafsirHit struct {
+	TafsirParagraph
+	Highlights []string `json:"highlights,omitempty"`
+}
+
+type TafsirResults struct {
+	Hits  []TafsirHit
+	Total int
+}
+
+type TafsirSearchRepository interface {
+	Index(paragraphs []TafsirParagraph) error
+	// Rebuild works like QuranSearchRepository.Rebuild.
+	Rebuild() (finish func(keep bool) error, err error)
+	// Search matches the clauses of params against the tafsir paragraphs.
+	// Filter, Sort, Highlight, Page and Limit apply as for ayahs.
+	Search(params SearchParams) (TafsirResults, error)
+	GetDocCount() (uint64, error)
+}
@@ -0,0 +1,24 @@
+package domain
+
+// DefaultTranslation is the Kemenag Indonesian translation served by the
+// upstream API.
+const DefaultTranslation = "id.kemenag"
+
+type TranslationInfo struct {
+	ID       string `json:"id"`
+	Language string `json:"language"`
+	Name     string `json:"name"`
+	// Translator is empty when the source does not name one.
+	Translator string `json:"translator,omitempty"`
+}
+
+// TranslationRepository serves translations other than DefaultTranslation
+// from local data files.
+type TranslationRepository interface {
+	// List returns DefaultTranslation first, then the loaded translations
+	// ordered by ID.
+	List() []TranslationInfo
+	// Get and Verse look translations up by ID ignoring case.
+	Get(id string) (TranslationInfo, bool)
+	Verse(id string, ref VerseRef) (string, bool)
+}
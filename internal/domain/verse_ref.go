@@ -0,0 +1,24 @@
+package domain
+
+import "strconv"
+
+type VerseRef struct {
+	Surah int `json:"surah"`
+	Ayah  int `json:"ayah"`
+}
+
+// String formats the reference as "surah:ayah", which is also the document
+// ID used by the search index.
+func (r VerseRef) String() string {
+	return strconv.Itoa(r.Surah) + ":" + strconv.Itoa(r.Ayah)
+}
+
+// Compare orders references in mushaf order, returning a negative number
+// when r comes before other, zero when they are equal and a positive number
+// otherwise.
+func (r VerseRef) Compare(other VerseRef) int {
+	if r.Surah != other.Surah {
+		return r.Surah - other.Surah
+	}
+	return r.Ayah - other.Ayah
+}
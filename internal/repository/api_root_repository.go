@@ -27,11 +27,41 @@ func (r *apiRootRepository) GetV1() (*domain.ApiRoot, error) {
 				Path:    "/api/v1/ayah/:id",
 				Example: "/api/v1/ayah/2",
 			},
+			"ayah_related": {
+				Method:  "GET",
+				Path:    "/api/v1/ayah/:id/related",
+				Example: "/api/v1/ayah/2:255/related",
+			},
+			"compare": {
+				Method:  "GET",
+				Path:    "/api/v1/compare/:ref?translations={ids}",
+				Example: "/api/v1/compare/2:255?translations=id.kemenag,en.sahih",
+			},
+			"translations": {
+				Method:  "GET",
+				Path:    "/api/v1/translations",
+				Example: "/api/v1/translations",
+			},
 			"search": {
 				Method:  "GET",
 				Path:    "/api/v1/search?q={query}",
 				Example: "/api/v1/search?q=orang beriman",
 			},
+			"search_advanced": {
+				Method:  "POST",
+				Path:    "/api/v1/search",
+				Example: "/api/v1/search",
+			},
+			"search_tafsir": {
+				Method:  "GET",
+				Path:    "/api/v1/search/tafsir?q={query}",
+				Example: "/api/v1/search/tafsir?q=perang uhud",
+			},
+			"search_suggest": {
+				Method:  "GET",
+				Path:    "/api/v1/search/suggest?q={prefix}",
+				Example: "/api/v1/search/suggest?q=orang ber",
+			},
 			"prayer_time": {
 				Method:  "GET",
 				Path:    "/api/v1/prayer-time",
@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"strings"
+
+	"github.com/blevesearch/bleve/v2/analysis"
+	_ "github.com/blevesearch/bleve/v2/analysis/lang/id"
+	"github.com/blevesearch/bleve/v2/registry"
+)
+
+const (
+	indonesianStemmer  = "stemmer_id"
+	indonesianAnalyzer = "indonesian"
+)
+
+// indonesianStemmerFilter strips Indonesian particles, possessive pronouns,
+// prefixes and suffixes so that e.g. "beriman", "keimanan" and "iman" share
+// a stem. It follows the dictionary-free algorithm of Tala (2003), as used
+// by Lucene's IndonesianStemmer, which trades some overstemming for not
+// needing a root word list.
+type indonesianStemmerFilter struct{}
+
+func (f *indonesianStemmerFilter) Filter(input analysis.TokenStream) analysis.TokenStream {
+	for _, token := range input {
+		token.Term = []byte(stemIndonesian(string(token.Term)))
+	}
+	return input
+}
+
+func indonesianStemmerConstructor(config map[string]interface{}, cache *registry.Cache) (analysis.TokenFilter, error) {
+	return &indonesianStemmerFilter{}, nil
+}
+
+func init() {
+	if err := registry.RegisterTokenFilter(indonesianStemmer, indonesianStemmerConstructor); err != nil {
+		panic(err)
+	}
+}
+
+const (
+	removedKe = 1 << iota
+	removedPeng
+	removedDi
+	removedMeng
+	removedTer
+	removedBer
+	removedPe
+)
+
+type indonesianStem struct {
+	word      string
+	syllables int
+	flags     int
+}
+
+// stemIndonesian expects a lowercase word.
+func stemIndonesian(word string) string {
+	s := &indonesianStem{word: word}
+	for _, r := range word {
+		if isIndonesianVowel(r) {
+			s.syllables++
+		}
+	}
+
+	if s.syllables > 2 {
+		s.removeSuffixOf([]string{"kah", "lah", "pun"})
+	}
+	if s.syllables > 2 {
+		s.removeSuffixOf([]string{"nya", "ku", "mu"})
+	}
+
+	if s.syllables > 2 && s.removeFirstOrderPrefix() {
+		if s.syllables > 2 && s.removeSuffix() && s.syllables > 2 {
+			s.removeSecondOrderPrefix()
+		}
+	} else {
+		if s.syllables > 2 {
+			s.removeSecondOrderPrefix()
+		}
+		if s.syllables > 2 {
+			s.removeSuffix()
+		}
+	}
+
+	return s.word
+}
+
+func isIndonesianVowel(r rune) bool {
+	return strings.ContainsRune("aeiou", r)
+}
+
+func (s *indonesianStem) removeSuffixOf(suffixes []string) bool {
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(s.word, suffix) {
+			s.word = strings.TrimSuffix(s.word, suffix)
+			s.syllables--
+			return true
+		}
+	}
+	return false
+}
+
+// removePrefix drops the first n bytes. A non-empty restore replaces the
+// letter after them, bringing back a root letter merged into the prefix
+// (menyapu -> sapu).
+func (s *indonesianStem) removePrefix(n int, flag int, restore string) bool {
+	s.word = restore + s.word[n+len(restore):]
+	s.flags |= flag
+	s.syllables--
+	return true
+}
+
+func (s *indonesianStem) vowelAt(i int) bool {
+	return len(s.word) > i && isIndonesianVowel(rune(s.word[i]))
+}
+
+func (s *indonesianStem) removeFirstOrderPrefix() bool {
+	w := s.word
+	switch {
+	case strings.HasPrefix(w, "meng"):
+		return s.removePrefix(4, removedMeng, "")
+	case strings.HasPrefix(w, "meny") && s.vowelAt(4):
+		return s.removePrefix(3, removedMeng, "s")
+	case strings.HasPrefix(w, "mem"), strings.HasPrefix(w, "men"):
+		return s.removePrefix(3, removedMeng, "")
+	case strings.HasPrefix(w, "me"):
+		return s.removePrefix(2, removedMeng, "")
+	case strings.HasPrefix(w, "peng"):
+		return s.removePrefix(4, removedPeng, "")
+	case strings.HasPrefix(w, "peny") && s.vowelAt(4):
+		return s.removePrefix(3, removedPeng, "s")
+	case strings.HasPrefix(w, "peny"):
+		return s.removePrefix(4, removedPeng, "")
+	case strings.HasPrefix(w, "pem") && s.vowelAt(3):
+		return s.removePrefix(2, removedPeng, "p")
+	case strings.HasPrefix(w, "pem"), strings.HasPrefix(w, "pen"):
+		return s.removePrefix(3, removedPeng, "")
+	case strings.HasPrefix(w, "pe"):
+		return s.removePrefix(2, removedPeng, "")
+	case strings.HasPrefix(w, "di"):
+		return s.removePrefix(2, removedDi, "")
+	case strings.HasPrefix(w, "ter"):
+		return s.removePrefix(3, removedTer, "")
+	case strings.HasPrefix(w, "ke"):
+		return s.removePrefix(2, removedKe, "")
+	}
+	return false
+}
+
+func (s *indonesianStem) removeSecondOrderPrefix() bool {
+	w := s.word
+	switch {
+	case strings.HasPrefix(w, "ber"), w == "belajar":
+		return s.removePrefix(3, removedBer, "")
+	case strings.HasPrefix(w, "be") && len(w) > 4 && !s.vowelAt(2) && w[3] == 'e' && w[4] == 'r':
+		return s.removePrefix(2, removedBer, "")
+	case strings.HasPrefix(w, "per"), w == "pelajar":
+		return s.removePrefix(3, 0, "")
+	case strings.HasPrefix(w, "pe"):
+		return s.removePrefix(2, removedPe, "")
+	}
+	return false
+}
+
+func (s *indonesianStem) removeSuffix() bool {
+	w := s.word
+	switch {
+	case strings.HasSuffix(w, "kan") && s.flags&(removedKe|removedPeng|removedPe) == 0:
+		s.word = w[:len(w)-3]
+	case strings.HasSuffix(w, "an") && s.flags&(removedDi|removedMeng|removedTer) == 0:
+		s.word = w[:len(w)-2]
+	case strings.HasSuffix(w, "i") && !strings.HasSuffix(w, "si") && s.flags&(removedBer|removedKe|removedPeng) == 0:
+		s.word = w[:len(w)-1]
+	default:
+		return false
+	}
+	s.syllables--
+	return true
+}
@@ -0,0 +1,30 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStemIndonesian(t *testing.T) {
+	tests := map[string]string{
+		"iman":        "iman",
+		"beriman":     "iman",
+		"keimanan":    "iman",
+		"bertakwalah": "takwa",
+		"ketakwaan":   "takwa",
+		"menyembah":   "sembah",
+		"pemukul":     "pukul",
+		"bekerja":     "kerja",
+		"diturunkan":  "turun",
+		"salat":       "salat",
+	}
+
+	for word, want := range tests {
+		assert.Equal(t, want, stemIndonesian(word), word)
+	}
+
+	// Without a root word list some stems are cut short, but every form of
+	// a word still shares one.
+	assert.Equal(t, stemIndonesian("rezeki"), stemIndonesian("rezekinya"))
+}
@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+
+	"github.com/blevesearch/bleve/v2"
+)
+
+// liveIndex is a bleve index that a rebuild replaces as a whole while it
+// keeps being searched, so that a new mapping takes effect and documents
+// left over from earlier builds go away.
+type liveIndex struct {
+	path string
+	// create makes an empty index with the current mapping.
+	create func(indexPath string) (bleve.Index, error)
+	// opened prepares an index after it is opened, as the constructor
+	// does for the first one.
+	opened func(index bleve.Index)
+
+	// mu guards current and next. Searches hold it for reading, replacing
+	// the index holds it for writing.
+	mu      sync.RWMutex
+	current bleve.Index
+	// next is the index being rebuilt, nil when no rebuild is running.
+	next bleve.Index
+}
+
+func newLiveIndex(path string, index bleve.Index, create func(string) (bleve.Index, error), opened func(bleve.Index)) *liveIndex {
+	return &liveIndex{path: path, current: index, create: create, opened: opened}
+}
+
+// acquire returns the index to search and the function releasing it.
+func (l *liveIndex) acquire() (bleve.Index, func()) {
+	l.mu.RLock()
+	return l.current, l.mu.RUnlock
+}
+
+// acquireExclusive is acquire for changes to the index mapping, which no
+// other search may see.
+func (l *liveIndex) acquireExclusive() (bleve.Index, func()) {
+	l.mu.Lock()
+	return l.current, l.mu.Unlock
+}
+
+// acquireTarget returns the index new documents go to, which is the one
+// being rebuilt while a rebuild runs.
+func (l *liveIndex) acquireTarget() (bleve.Index, func()) {
+	l.mu.RLock()
+	if l.next != nil {
+		return l.next, l.mu.RUnlock
+	}
+	return l.current, l.mu.RUnlock
+}
+
+func (l *liveIndex) rebuildPath() string {
+	return l.path + ".rebuild"
+}
+
+// rebuild creates an empty index next to the current one for documents to
+// go to. The returned function replaces the current index with it when
+// keep is true and discards it otherwise.
+func (l *liveIndex) rebuild() (func(keep bool) error, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.next != nil {
+		return nil, fmt.Errorf("the index at %s is already being rebuilt", l.path)
+	}
+
+	rebuildPath := l.rebuildPath()
+	if err := os.RemoveAll(rebuildPath); err != nil {
+		return nil, fmt.Errorf("failed to remove old rebuild at %s: %w", rebuildPath, err)
+	}
+	next, err := l.create(rebuildPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create index at %s: %w", rebuildPath, err)
+	}
+	l.next = next
+	log.Printf("Rebuilding search index at %s", rebuildPath)
+
+	return l.finishRebuild, nil
+}
+
+// close closes the current index and discards a rebuild still running.
+func (l *liveIndex) close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var err error
+	if l.next != nil {
+		err = errors.Join(l.next.Close(), os.RemoveAll(l.rebuildPath()))
+		l.next = nil
+	}
+	return errors.Join(err, l.current.Close())
+}
+
+// finishRebuild replaces the current index with the rebuilt one when keep
+// is true. Until the rebuilt index opens the replaced one is kept on disk,
+// and it is opened again if the replacement fails, so searches go on.
+func (l *liveIndex) finishRebuild(keep bool) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	next := l.next
+	if next == nil {
+		return nil
+	}
+	l.next = nil
+
+	rebuildPath := l.rebuildPath()
+	if !keep {
+		return errors.Join(next.Close(), os.RemoveAll(rebuildPath))
+	}
+
+	if err := next.Close(); err != nil {
+		return errors.Join(fmt.Errorf("failed to close rebuilt index at %s: %w", rebuildPath, err), os.RemoveAll(rebuildPath))
+	}
+	if err := l.current.Close(); err != nil {
+		return errors.Join(fmt.Errorf("failed to close index at %s: %w", l.path, err), l.open())
+	}
+
+	oldPath := l.path + ".old"
+	err := os.RemoveAll(oldPath)
+	if err == nil {
+		err = os.Rename(l.path, oldPath)
+	}
+	if err == nil {
+		if err = os.Rename(rebuildPath, l.path); err != nil {
+			// Put the current index back.
+			_ = os.Rename(oldPath, l.path)
+		}
+	}
+	if err != nil {
+		return errors.Join(fmt.Errorf("failed to replace index at %s: %w", l.path, err), l.open())
+	}
+
+	if err := l.open(); err != nil {
+		// Put the replaced index back in place of the one that fails to open.
+		restoreErr := os.RemoveAll(l.path)
+		if restoreErr == nil {
+			restoreErr = os.Rename(oldPath, l.path)
+		}
+		if restoreErr == nil {
+			restoreErr = l.open()
+		}
+		return errors.Join(fmt.Errorf("failed to replace index at %s: %w", l.path, err), restoreErr)
+	}
+
+	if err := os.RemoveAll(oldPath); err != nil {
+		log.Printf("Warning: Failed to remove replaced index at %s: %v", oldPath, err)
+	}
+	log.Printf("Replaced search index at %s with the rebuilt one", l.path)
+	return nil
+}
+
+// open makes the index at path the current one. The current index is left
+// as it is when the open fails.
+func (l *liveIndex) open() error {
+	index, err := bleve.Open(l.path)
+	if err != nil {
+		return fmt.Errorf("failed to open index at %s: %w", l.path, err)
+	}
+	l.opened(index)
+	l.current = index
+	return nil
+}
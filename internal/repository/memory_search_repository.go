@@ -0,0 +1,1002 @@
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"maps"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/anugrahsputra/go-quran-api/config"
+	"github.com/anugrahsputra/go-quran-api/internal/domain"
+)
+
+// memoryDocument is an indexed verse with the terms of each index field,
+// named and analyzed like the fields of the bleve index.
+type memoryDocument struct {
+	ayah domain.SearchedAyah
+	// terms holds the terms of each field in text order.
+	terms map[string][]string
+	// freqs counts the terms of each field.
+	freqs map[string]map[string]int
+}
+
+// memorySearchRepository searches verses held in memory, for tests and for
+// deployments too small to need an index on disk. It matches and weighs
+// queries like the bleve repository, scoring hits by TF-IDF whatever the
+// scoring model, and is empty until indexed.
+type memorySearchRepository struct {
+	fuzziness int
+	boosts    map[string]float64
+	ngramMin  int
+	ngramMax  int
+
+	mu   sync.RWMutex
+	docs map[string]*memoryDocument
+	// rebuilt holds the documents indexed during a rebuild, nil when none
+	// is running.
+	rebuilt map[string]*memoryDocument
+	// ordered lists the documents in mushaf order.
+	ordered []*memoryDocument
+	// docFreqs counts the documents holding each term, per field.
+	docFreqs    map[string]map[string]int
+	spelling    *termDictionary
+	completions *termDictionary
+}
+
+func NewMemorySearchRepository(cfg *config.Config) (domain.QuranSearchRepository, error) {
+	if err := checkBoosts(cfg.Search.Boosts); err != nil {
+		return nil, err
+	}
+	if _, err := scoringModelOrDefault(cfg.Search.ScoringModel); err != nil {
+		return nil, err
+	}
+
+	ngramMin, ngramMax := ngramSizes(cfg.Search)
+	return &memorySearchRepository{
+		fuzziness:   cfg.Search.Fuzziness,
+		boosts:      cfg.Search.Boosts,
+		ngramMin:    ngramMin,
+		ngramMax:    ngramMax,
+		docs:        make(map[string]*memoryDocument),
+		spelling:    newTermDictionary("Translation", "Latin"),
+		completions: newTermDictionary("Translation", "Latin", "Topic"),
+	}, nil
+}
+
+// analyze splits text into the terms of an index field.
+func (r *memorySearchRepository) analyze(field, text string) []string {
+	switch {
+	case strings.HasSuffix(field, "_stem"):
+		tokens := tokenize(text)
+		for i, token := range tokens {
+			tokens[i] = stemIndonesian(token)
+		}
+		return tokens
+	case strings.HasSuffix(field, "_ngram"):
+		var grams []string
+		for _, token := range tokenize(text) {
+			runes := []rune(token)
+			for size := r.ngramMin; size <= r.ngramMax; size++ {
+				for i := 0; i+size <= len(runes); i++ {
+					grams = append(grams, string(runes[i:i+size]))
+				}
+			}
+		}
+		return grams
+	case field == "Kitabah":
+		// Diacritics are dropped so that they do not split words.
+		return tokenize(strings.Map(func(r rune) rune {
+			if unicode.Is(unicode.Mn, r) {
+				return -1
+			}
+			return r
+		}, text))
+	}
+	return tokenize(text)
+}
+
+// memoryFields returns the text of each index field of a verse.
+func memoryFields(ayah domain.SearchedAyah) map[string]string {
+	fields := map[string]string{
+		"Latin":             ayah.Latin,
+		"Latin_ngram":       ayah.Latin,
+		"Translation":       ayah.Translation,
+		"Translation_ngram": ayah.Translation,
+		"Translation_stem":  ayah.Translation,
+		"Tafsir":            ayah.Tafsir,
+		"Tafsir_stem":       ayah.Tafsir,
+		"Wajiz":             ayah.Wajiz,
+		"Wajiz_stem":        ayah.Wajiz,
+		"SababNuzul":        ayah.SababNuzul,
+		"SababNuzul_stem":   ayah.SababNuzul,
+		"Kosakata":          ayah.Kosakata,
+		"Conclusion":        ayah.Conclusion,
+		"Conclusion_stem":   ayah.Conclusion,
+		"Kitabah":           ayah.Kitabah,
+		"SurahName":         ayah.SurahName,
+		"Topic":             ayah.Topic,
+	}
+	for translationID, text := range ayah.Translations {
+		fields[translationField(translationID)] = text
+	}
+	return fields
+}
+
+func (r *memorySearchRepository) Index(ayahs []domain.SearchedAyah) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	docs := r.docs
+	if r.rebuilt != nil {
+		docs = r.rebuilt
+	}
+	for _, ayah := range ayahs {
+		doc := &memoryDocument{
+			ayah:  ayah,
+			terms: make(map[string][]string),
+			freqs: make(map[string]map[string]int),
+		}
+		for field, text := range memoryFields(ayah) {
+			terms := r.analyze(field, text)
+			if len(terms) == 0 {
+				continue
+			}
+			freqs := make(map[string]int)
+			for _, term := range terms {
+				freqs[term]++
+			}
+			doc.terms[field], doc.freqs[field] = terms, freqs
+		}
+		docs[verseID(ayah)] = doc
+	}
+
+	log.Printf("Indexed %d ayahs in memory", len(ayahs))
+	if r.rebuilt == nil {
+		r.refresh()
+	}
+	return nil
+}
+
+// Rebuild collects the verses indexed until the returned function is
+// called apart from the searched ones, which they replace when keep is
+// true.
+func (r *memorySearchRepository) Rebuild() (func(keep bool) error, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.rebuilt != nil {
+		return nil, errors.New("the memory index is already being rebuilt")
+	}
+	r.rebuilt = make(map[string]*memoryDocument)
+
+	return func(keep bool) error {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		if keep && r.rebuilt != nil {
+			r.docs = r.rebuilt
+			r.refresh()
+		}
+		r.rebuilt = nil
+		return nil
+	}, nil
+}
+
+// refresh recomputes what is derived from the documents. It expects mu to
+// be held for writing.
+func (r *memorySearchRepository) refresh() {
+	r.ordered = make([]*memoryDocument, 0, len(r.docs))
+	r.docFreqs = make(map[string]map[string]int)
+	for _, doc := range r.docs {
+		r.ordered = append(r.ordered, doc)
+		for field, freqs := range doc.freqs {
+			if r.docFreqs[field] == nil {
+				r.docFreqs[field] = make(map[string]int)
+			}
+			for term := range freqs {
+				r.docFreqs[field][term]++
+			}
+		}
+	}
+	sort.Slice(r.ordered, func(i, j int) bool {
+		return verseRef(r.ordered[i].ayah).Compare(verseRef(r.ordered[j].ayah)) < 0
+	})
+	r.spelling.build(r.docFreqs)
+	r.completions.build(r.docFreqs)
+}
+
+func verseRef(ayah domain.SearchedAyah) domain.VerseRef {
+	return domain.VerseRef{Surah: ayah.SurahNumber, Ayah: ayah.AyahNumber}
+}
+
+// verseID is the document ID of ayah.
+func verseID(ayah domain.SearchedAyah) string {
+	return verseRef(ayah).String()
+}
+
+// memoryQuery matches one field of a document, like one of the bleve
+// queries built by fieldQueries.
+type memoryQuery struct {
+	field string
+	boost float64
+	// words are the analyzed query words, each with the terms it matches
+	// and their edit distance.
+	words  []map[string]int
+	phrase bool
+	// all requires every word to match.
+	all bool
+	// numeric is set for a number range over a numeric field.
+	numeric      string
+	lower, upper int
+}
+
+type memoryClause struct {
+	occur   string
+	queries []memoryQuery
+}
+
+// MarshalJSON shows the query as the query_tree of an explained search.
+func (q memoryQuery) MarshalJSON() ([]byte, error) {
+	tree := struct {
+		Field string  `json:"field"`
+		Boost float64 `json:"boost"`
+		// Words lists the terms each query word matches with their edit
+		// distance.
+		Words   []map[string]int `json:"words,omitempty"`
+		Phrase  bool             `json:"phrase,omitempty"`
+		All     bool             `json:"all,omitempty"`
+		Numeric string           `json:"numeric,omitempty"`
+		Min     *int             `json:"min,omitempty"`
+		Max     *int             `json:"max,omitempty"`
+	}{Field: q.field, Boost: q.boost, Words: q.words, Phrase: q.phrase, All: q.all, Numeric: q.numeric}
+	if q.numeric != "" {
+		tree.Min, tree.Max = &q.lower, &q.upper
+	}
+	return json.Marshal(tree)
+}
+
+// MarshalJSON shows the clause as the query_tree of an explained search.
+func (c memoryClause) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Occur   string        `json:"occur"`
+		Queries []memoryQuery `json:"queries"`
+	}{c.occur, c.queries})
+}
+
+// memoryMatch is a document matching a search, with how it matched.
+type memoryMatch struct {
+	doc   *memoryDocument
+	score float64
+	// terms holds the matched terms of each field.
+	terms         map[string]map[string]bool
+	contributions map[string]*domain.FieldContribution
+	sortValues    []float64
+}
+
+func (m *memoryMatch) add(field, term string, boost, score float64) {
+	if m.terms[field] == nil {
+		m.terms[field] = make(map[string]bool)
+	}
+	m.terms[field][term] = true
+
+	contribution := m.contributions[field]
+	if contribution == nil {
+		contribution = &domain.FieldContribution{Field: field}
+		m.contributions[field] = contribution
+	}
+	contribution.Score += score
+	contribution.Terms = append(contribution.Terms, domain.TermContribution{Term: term, Boost: boost, Score: score})
+}
+
+func (r *memorySearchRepository) Search(params domain.SearchParams) (*domain.IndexResults, error) {
+	from, size := params.Window()
+
+	fuzziness := r.fuzziness
+	if params.Fuzziness != nil {
+		fuzziness = *params.Fuzziness
+	}
+	params.Boosts = fieldBoosts(r.boosts, params.Boosts)
+
+	sortFields := params.Sort
+	if len(sortFields) == 0 {
+		sortFields = []domain.SortField{{Field: domain.SortScore, Desc: true}}
+	}
+	order := newMemorySortOrder(sortFields)
+	var after []float64
+	for _, value := range params.SearchAfter {
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid search after value %q", value)
+		}
+		after = append(after, number)
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	clauses := r.buildClauses(params, fuzziness)
+	var matches []*memoryMatch
+	for _, doc := range r.ordered {
+		if !params.Filter.Matches(doc.ayah) || slices.Contains(params.Exclude, verseID(doc.ayah)) {
+			continue
+		}
+		if match := r.match(clauses, doc); match != nil {
+			match.sortValues = order.values(match)
+			matches = append(matches, match)
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return order.compare(matches[i].sortValues, matches[j].sortValues) < 0
+	})
+
+	results := &domain.IndexResults{
+		Total:  len(matches),
+		Facets: memoryFacets(matches, params.Facets, params.FacetSize),
+	}
+	if params.Explain {
+		results.Query = clauses
+	}
+
+	if len(after) > 0 {
+		start := sort.Search(len(matches), func(i int) bool {
+			return order.compare(matches[i].sortValues, after) > 0
+		})
+		matches = matches[start:]
+	} else {
+		matches = matches[min(from, len(matches)):]
+	}
+	matches = matches[:min(size, len(matches))]
+
+	translations := searchedTranslations(params)
+	for _, match := range matches {
+		results.Hits = append(results.Hits, r.hit(match, params, translations, order))
+	}
+	return results, nil
+}
+
+// buildClauses resolves the query clauses to field queries, the way
+// buildTextQuery does for bleve.
+func (r *memorySearchRepository) buildClauses(params domain.SearchParams, fuzziness int) []memoryClause {
+	clauses := params.Clauses
+	if len(clauses) == 0 {
+		clauses = []domain.QueryClause{{Text: params.Query, Occur: domain.OccurShould}}
+	}
+
+	fields := params.Fields
+	if len(fields) == 0 {
+		fields = domain.DefaultSearchFields
+	}
+
+	memoryClauses := make([]memoryClause, 0, len(clauses))
+	for _, clause := range clauses {
+		clauseFields := fields
+		if clause.Field != "" {
+			clauseFields = []string{clause.Field}
+		}
+		clauseFuzziness := fuzziness
+		if clause.Occur == domain.OccurMustNot {
+			clauseFuzziness = 0
+		}
+		clauseBoost := clause.Boost
+		if clauseBoost <= 0 {
+			clauseBoost = 1.0
+		}
+
+		memoryClause := memoryClause{occur: clause.Occur}
+		for _, key := range clauseFields {
+			for _, field := range lookupSearchFields(key, params) {
+				memoryClause.queries = append(memoryClause.queries, r.fieldQueries(clause, field, clauseBoost, clauseFuzziness)...)
+			}
+		}
+		memoryClauses = append(memoryClauses, memoryClause)
+	}
+	return memoryClauses
+}
+
+func (r *memorySearchRepository) fieldQueries(clause domain.QueryClause, field searchField, clauseBoost float64, fuzziness int) []memoryQuery {
+	var queries []memoryQuery
+	field.boost *= clauseBoost
+
+	if field.numeric != "" {
+		if lower, upper, ok := parseNumberRange(clause.Text); ok {
+			return []memoryQuery{{field: field.numeric, boost: field.boost, numeric: field.numeric, lower: lower, upper: upper}}
+		}
+	}
+	if field.name == "" {
+		return nil
+	}
+
+	if clause.Phrase {
+		queries = append(queries, r.phraseQuery(clause.Text, field.name, field.boost))
+	} else {
+		matchFuzziness := 0
+		if field.fuzzy {
+			matchFuzziness = fuzziness
+		}
+		matchQuery := r.matchQuery(clause.Text, field.name, field.boost, matchFuzziness)
+		matchQuery.all = field.matchAll
+		queries = append(queries, matchQuery)
+
+		if field.stem != "" {
+			queries = append(queries, r.matchQuery(clause.Text, field.stem, field.boost*stemWeight, 0))
+		}
+		if field.ngram != "" && clause.Occur == domain.OccurShould {
+			queries = append(queries, r.matchQuery(clause.Text, field.ngram, clauseBoost*field.weight*field.ngramBoost, 0))
+		}
+	}
+
+	for _, synonym := range clause.Synonyms {
+		if strings.Contains(synonym, " ") {
+			queries = append(queries, r.phraseQuery(synonym, field.name, field.boost*synonymWeight))
+			continue
+		}
+		queries = append(queries, r.matchQuery(synonym, field.name, field.boost*synonymWeight, 0))
+	}
+
+	return queries
+}
+
+// matchQuery matches any word of text, or terms within fuzziness edits of
+// it.
+func (r *memorySearchRepository) matchQuery(text, field string, boost float64, fuzziness int) memoryQuery {
+	query := memoryQuery{field: field, boost: boost}
+	var seen []string
+	for _, word := range r.analyze(field, text) {
+		if slices.Contains(seen, word) {
+			continue
+		}
+		seen = append(seen, word)
+
+		terms := map[string]int{word: 0}
+		if fuzziness > 0 {
+			for term := range r.docFreqs[field] {
+				if abs(len([]rune(term))-len([]rune(word))) > fuzziness {
+					continue
+				}
+				if distance := levenshtein(word, term, fuzziness); distance <= fuzziness {
+					terms[term] = distance
+				}
+			}
+		}
+		query.words = append(query.words, terms)
+	}
+	return query
+}
+
+func (r *memorySearchRepository) phraseQuery(text, field string, boost float64) memoryQuery {
+	query := memoryQuery{field: field, boost: boost, phrase: true}
+	for _, word := range r.analyze(field, text) {
+		query.words = append(query.words, map[string]int{word: 0})
+	}
+	return query
+}
+
+// match scores a document against the clauses, returning nil when it does
+// not match them.
+func (r *memorySearchRepository) match(clauses []memoryClause, doc *memoryDocument) *memoryMatch {
+	match := &memoryMatch{
+		doc:           doc,
+		terms:         make(map[string]map[string]bool),
+		contributions: make(map[string]*domain.FieldContribution),
+	}
+	hasMust, shouldMatched := false, false
+	for _, clause := range clauses {
+		clauseMatch := &memoryMatch{
+			doc:           doc,
+			terms:         make(map[string]map[string]bool),
+			contributions: make(map[string]*domain.FieldContribution),
+		}
+		matched := false
+		for _, query := range clause.queries {
+			matched = r.evaluate(query, clauseMatch) || matched
+		}
+
+		switch clause.occur {
+		case domain.OccurMust:
+			hasMust = true
+			if !matched {
+				return nil
+			}
+		case domain.OccurMustNot:
+			if matched {
+				return nil
+			}
+			continue
+		default:
+			shouldMatched = shouldMatched || matched
+		}
+		if matched {
+			match.merge(clauseMatch)
+		}
+	}
+	if !hasMust && !shouldMatched {
+		return nil
+	}
+	return match
+}
+
+func (m *memoryMatch) merge(other *memoryMatch) {
+	m.score += other.score
+	for field, contribution := range other.contributions {
+		for _, term := range contribution.Terms {
+			m.add(field, term.Term, term.Boost, term.Score)
+		}
+	}
+}
+
+// evaluate adds the terms a query matches in the document to match.
+func (r *memorySearchRepository) evaluate(query memoryQuery, match *memoryMatch) bool {
+	ayah := match.doc.ayah
+	if query.numeric != "" {
+		value := numericField(ayah, query.numeric)
+		if value < query.lower || value > query.upper {
+			return false
+		}
+		match.score += query.boost
+		match.add(query.field, strconv.Itoa(value), query.boost, query.boost)
+		return true
+	}
+	if len(query.words) == 0 {
+		return false
+	}
+
+	if query.phrase {
+		words := make([]string, len(query.words))
+		for i, terms := range query.words {
+			for term := range terms {
+				words[i] = term
+			}
+		}
+		count := phraseCount(match.doc.terms[query.field], words)
+		if count == 0 {
+			return false
+		}
+		for _, word := range words {
+			score := query.boost * r.termWeight(match.doc, query.field, word, count)
+			match.score += score
+			match.add(query.field, word, query.boost, score)
+		}
+		return true
+	}
+
+	type termMatch struct {
+		term  string
+		score float64
+	}
+	var matched []termMatch
+	for _, terms := range query.words {
+		wordMatched := false
+		// Terms are summed in a fixed order, so that the score, which hits
+		// are sorted and continued after by, is the same on every search.
+		for _, term := range slices.Sorted(maps.Keys(terms)) {
+			distance := terms[term]
+			tf := match.doc.freqs[query.field][term]
+			if tf == 0 {
+				continue
+			}
+			// Fuzzy matches rank below the word itself.
+			score := query.boost * r.termWeight(match.doc, query.field, term, tf) / float64(1+distance)
+			matched = append(matched, termMatch{term: term, score: score})
+			wordMatched = true
+		}
+		if query.all && !wordMatched {
+			return false
+		}
+	}
+	for _, term := range matched {
+		match.score += term.score
+		match.add(query.field, term.term, query.boost, term.score)
+	}
+	return len(matched) > 0
+}
+
+// termWeight is the TF-IDF weight of a term occurring tf times in a field
+// of doc, normalized by the length of the field.
+func (r *memorySearchRepository) termWeight(doc *memoryDocument, field, term string, tf int) float64 {
+	idf := 1 + math.Log(float64(len(r.ordered))/float64(r.docFreqs[field][term]+1))
+	return math.Sqrt(float64(tf)) * idf / math.Sqrt(float64(len(doc.terms[field])))
+}
+
+// phraseCount counts the occurrences of words next to each other in terms.
+func phraseCount(terms, words []string) int {
+	count := 0
+	for i := 0; i+len(words) <= len(terms); i++ {
+		if slices.Equal(terms[i:i+len(words)], words) {
+			count++
+		}
+	}
+	return count
+}
+
+func numericField(ayah domain.SearchedAyah, field string) int {
+	switch field {
+	case "SurahNumber":
+		return ayah.SurahNumber
+	case "AyahNumber":
+		return ayah.AyahNumber
+	case "Juz":
+		return ayah.Juz
+	case "Page":
+		return ayah.Page
+	}
+	return 0
+}
+
+type memorySortField struct {
+	// field is a numeric field, or empty for the score.
+	field string
+	desc  bool
+}
+
+// memorySortOrder mirrors buildSortOrder, breaking ties in mushaf order.
+type memorySortOrder []memorySortField
+
+func newMemorySortOrder(fields []domain.SortField) memorySortOrder {
+	var order memorySortOrder
+	for _, field := range fields {
+		switch field.Field {
+		case domain.SortScore:
+			order = append(order, memorySortField{desc: field.Desc})
+		case domain.SortSurah:
+			order = append(order, memorySortField{"SurahNumber", field.Desc}, memorySortField{"AyahNumber", field.Desc})
+		case domain.SortSurahNumber:
+			order = append(order, memorySortField{"SurahNumber", field.Desc})
+		case domain.SortJuz:
+			order = append(order, memorySortField{"Juz", field.Desc})
+		case domain.SortPage:
+			order = append(order, memorySortField{"Page", field.Desc})
+		}
+	}
+	return append(order, memorySortField{field: "SurahNumber"}, memorySortField{field: "AyahNumber"})
+}
+
+func (o memorySortOrder) values(match *memoryMatch) []float64 {
+	values := make([]float64, len(o))
+	for i, field := range o {
+		if field.field == "" {
+			values[i] = match.score
+		} else {
+			values[i] = float64(numericField(match.doc.ayah, field.field))
+		}
+	}
+	return values
+}
+
+// compare orders sort values, which may be cut short when passed as
+// domain.SearchParams.SearchAfter.
+func (o memorySortOrder) compare(a, b []float64) int {
+	for i, field := range o {
+		if i >= len(a) || i >= len(b) || a[i] == b[i] {
+			continue
+		}
+		less := a[i] < b[i]
+		if field.desc {
+			less = !less
+		}
+		if less {
+			return -1
+		}
+		return 1
+	}
+	return 0
+}
+
+func (o memorySortOrder) format(values []float64) []string {
+	formatted := make([]string, len(values))
+	for i, value := range values {
+		formatted[i] = strconv.FormatFloat(value, 'g', -1, 64)
+	}
+	return formatted
+}
+
+// memoryFacets counts the matches per value of each facet, most frequent
+// first.
+func memoryFacets(matches []*memoryMatch, facets []string, size int) map[string][]domain.FacetCount {
+	if size < 1 {
+		size = 10
+	}
+
+	var results map[string][]domain.FacetCount
+	for _, facet := range facets {
+		var value func(domain.SearchedAyah) string
+		switch facet {
+		case domain.FacetSurah:
+			value = func(ayah domain.SearchedAyah) string { return strconv.Itoa(ayah.SurahNumber) }
+		case domain.FacetJuz:
+			value = func(ayah domain.SearchedAyah) string { return strconv.Itoa(ayah.Juz) }
+		case domain.FacetTopic:
+			value = func(ayah domain.SearchedAyah) string { return ayah.Topic }
+		default:
+			continue
+		}
+
+		counts := make(map[string]int)
+		for _, match := range matches {
+			if v := value(match.doc.ayah); v != "" && v != "0" {
+				counts[v]++
+			}
+		}
+		facetCounts := []domain.FacetCount{}
+		for v, count := range counts {
+			facetCounts = append(facetCounts, domain.FacetCount{Value: v, Count: count})
+		}
+		sort.Slice(facetCounts, func(i, j int) bool {
+			if facetCounts[i].Count != facetCounts[j].Count {
+				return facetCounts[i].Count > facetCounts[j].Count
+			}
+			return facetCounts[i].Value < facetCounts[j].Value
+		})
+		if len(facetCounts) > size {
+			facetCounts = facetCounts[:size]
+		}
+
+		if results == nil {
+			results = make(map[string][]domain.FacetCount)
+		}
+		results[facet] = facetCounts
+	}
+	return results
+}
+
+// hit returns the verse of a match with the best matching translation as
+// Translation, like selectTranslation does for bleve hits.
+func (r *memorySearchRepository) hit(match *memoryMatch, params domain.SearchParams, translations []searchedTranslation, order memorySortOrder) domain.IndexHit {
+	best, bestScore := translations[0], 0.0
+	if len(translations) > 1 {
+		for _, translation := range translations {
+			score := float64(len(match.terms[translation.field]))
+			if translation.id == domain.DefaultTranslation && score == 0 {
+				// Partial and stemmed matches count as half a term.
+				defaultField := searchFields[domain.FieldTranslation]
+				if len(match.terms[defaultField.ngram]) > 0 || len(match.terms[defaultField.stem]) > 0 {
+					score = 0.5
+				}
+			}
+			if score*translation.weight > bestScore {
+				best, bestScore = translation, score*translation.weight
+			}
+		}
+	}
+
+	ayah := match.doc.ayah
+	if best.id != domain.DefaultTranslation {
+		ayah.Translation = ayah.Translations[best.id]
+	}
+	ayah.Translations = nil
+	if params.OmitTafsir {
+		ayah.Tafsir, ayah.Wajiz, ayah.SababNuzul, ayah.Kosakata, ayah.Conclusion = "", "", "", "", ""
+	}
+
+	hit := domain.IndexHit{
+		SearchedAyah:       ayah,
+		Score:              match.score,
+		MatchedTranslation: best.id,
+		SortValues:         order.format(match.sortValues),
+	}
+	if params.Highlight != "" {
+		hit.Highlights = r.highlights(match, ayah, best.field, params.Highlight)
+	}
+	if params.Explain {
+		hit.Explanation = newScoreExplanation(match.score, match.contributions, nil)
+	}
+	return hit
+}
+
+// highlights marks the matched words of each returned field, taking
+// translationField as the field of the returned translation.
+func (r *memorySearchRepository) highlights(match *memoryMatch, ayah domain.SearchedAyah, translationField, style string) map[string][]string {
+	texts := map[string]string{
+		translationField: ayah.Translation,
+		"Latin":          ayah.Latin,
+		"Tafsir":         ayah.Tafsir,
+		"Topic":          ayah.Topic,
+		"Wajiz":          ayah.Wajiz,
+		"SababNuzul":     ayah.SababNuzul,
+		"Kosakata":       ayah.Kosakata,
+		"Conclusion":     ayah.Conclusion,
+		"Kitabah":        ayah.Kitabah,
+		"SurahName":      ayah.SurahName,
+	}
+
+	var highlights map[string][]string
+	for field, text := range texts {
+		key := highlightFields[field]
+		if field == translationField {
+			key = domain.FieldTranslation
+		}
+		exact, stemmed := match.terms[field], match.terms[field+"_stem"]
+		if text == "" || key == "" || (len(exact) == 0 && len(stemmed) == 0) {
+			continue
+		}
+
+		matched := func(word string) bool {
+			for _, term := range r.analyze(field, word) {
+				if exact[term] {
+					return true
+				}
+			}
+			for _, term := range r.analyze(field+"_stem", word) {
+				if stemmed[term] {
+					return true
+				}
+			}
+			return false
+		}
+		if fragment, ok := highlightFragment(text, matched, style); ok {
+			if highlights == nil {
+				highlights = make(map[string][]string)
+			}
+			highlights[key] = []string{fragment}
+		}
+	}
+	return highlights
+}
+
+// highlightFragment marks the words of text for which matched is true,
+// keeping about snippetSize characters from just before the first one.
+func highlightFragment(text string, matched func(string) bool, style string) (string, bool) {
+	isWord := func(r rune) bool {
+		return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r)
+	}
+
+	runes := []rune(text)
+	type span struct{ start, end int }
+	var spans []span
+	for i := 0; i < len(runes); {
+		if !isWord(runes[i]) {
+			i++
+			continue
+		}
+		j := i
+		for j < len(runes) && isWord(runes[j]) {
+			j++
+		}
+		if matched(string(runes[i:j])) {
+			spans = append(spans, span{i, j})
+		}
+		i = j
+	}
+	if len(spans) == 0 {
+		return "", false
+	}
+
+	start, end := 0, len(runes)
+	if end > snippetSize {
+		start = max(0, spans[0].start-snippetSize/4)
+		end = min(len(runes), start+snippetSize)
+	}
+
+	open, close := "", ""
+	escape := func(s string) string { return s }
+	if style == domain.HighlightHTML {
+		open, close, escape = "<mark>", "</mark>", html.EscapeString
+	}
+
+	var fragment strings.Builder
+	pos := start
+	for _, span := range spans {
+		if span.start < start || span.end > end {
+			continue
+		}
+		fragment.WriteString(escape(string(runes[pos:span.start])))
+		fragment.WriteString(open + escape(string(runes[span.start:span.end])) + close)
+		pos = span.end
+	}
+	fragment.WriteString(escape(string(runes[pos:end])))
+	return fragment.String(), true
+}
+
+// Related finds similar verses like the bleve repository does: the most
+// distinctive terms of the verse are matched against the other verses,
+// and verses sharing its topic get the weight of the best term.
+func (r *memorySearchRepository) Related(id string, limit int) (*domain.IndexResults, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	source := r.docs[id]
+	if source == nil {
+		return nil, nil
+	}
+
+	docCount := uint64(len(r.ordered))
+	var terms []relatedTerm
+	for _, field := range relatedFields {
+		fieldTerms, err := weighRelatedTerms(field, source.terms[field.field], docCount, func(term string) (uint64, error) {
+			return uint64(r.docFreqs[field.field][term]), nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, fieldTerms...)
+	}
+	terms = topRelatedTerms(terms)
+	if len(terms) == 0 {
+		return &domain.IndexResults{}, nil
+	}
+
+	type relatedMatch struct {
+		doc    *memoryDocument
+		score  float64
+		counts map[string]int
+	}
+	var matches []relatedMatch
+	for _, doc := range r.ordered {
+		if doc == source {
+			continue
+		}
+
+		match := relatedMatch{doc: doc, counts: make(map[string]int)}
+		for _, term := range terms {
+			if tf := doc.freqs[term.field][term.term]; tf > 0 {
+				match.score += term.score * r.termWeight(doc, term.field, term.term, tf)
+				match.counts[term.term] += tf
+			}
+		}
+		if source.ayah.Topic != "" && doc.ayah.Topic == source.ayah.Topic {
+			match.score += terms[0].score
+		}
+		if match.score > 0 {
+			matches = append(matches, match)
+		}
+	}
+	// Verses are in mushaf order, which breaks ties.
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].score > matches[j].score })
+
+	results := &domain.IndexResults{Total: len(matches)}
+	for _, match := range matches[:min(limit, len(matches))] {
+		ayah := match.doc.ayah
+		ayah.Translations = nil
+		ayah.Tafsir, ayah.Wajiz, ayah.SababNuzul, ayah.Kosakata, ayah.Conclusion = "", "", "", "", ""
+		results.Hits = append(results.Hits, domain.IndexHit{
+			SearchedAyah: ayah,
+			Score:        match.score,
+			MatchedTerms: match.counts,
+		})
+	}
+	return results, nil
+}
+
+func (r *memorySearchRepository) SpellingSuggestions(query string, limit int) ([]string, error) {
+	return r.spelling.suggestCorrections(query, limit), nil
+}
+
+func (r *memorySearchRepository) Complete(prefix string, limit int) ([]domain.Completion, error) {
+	return r.completions.complete(prefix, limit), nil
+}
+
+func (r *memorySearchRepository) GetDocument(id string) (*domain.SearchedAyah, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	doc := r.docs[id]
+	if doc == nil {
+		return nil, nil
+	}
+	ayah := doc.ayah
+	ayah.Translations = nil
+	return &ayah, nil
+}
+
+func (r *memorySearchRepository) GetDocCount() (uint64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return uint64(len(r.docs)), nil
+}
+
+func (r *memorySearchRepository) IsHealthy() bool {
+	return true
+}
+
+// Close has nothing to release, as the index holds no files.
+func (r *memorySearchRepository) Close() error {
+	return nil
+}
@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/anugrahsputra/go-quran-api/config"
+	"github.com/anugrahsputra/go-quran-api/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemorySearchRepository_Explain(t *testing.T) {
+	repo, err := NewMemorySearchRepository(&config.Config{})
+	require.NoError(t, err)
+	require.NoError(t, repo.Index(testAyahs))
+
+	result, err := repo.Search(domain.SearchParams{
+		Clauses: []domain.QueryClause{{Text: "zakat", Occur: domain.OccurMust}},
+		Fields:  []string{domain.FieldTranslation},
+		Page:    1,
+		Limit:   10,
+		Explain: true,
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Hits, 1)
+
+	tree, err := json.Marshal(result.Query)
+	require.NoError(t, err)
+	assert.Contains(t, string(tree), `{"occur":"must","queries":[{"field":"Translation","boost":5,"words":[{"zakat":0}]}`)
+
+	explanation := result.Hits[0].Explanation
+	require.NotNil(t, explanation)
+	assert.Equal(t, result.Hits[0].Score, explanation.Score)
+	require.NotEmpty(t, explanation.Fields)
+	assert.Equal(t, "Translation", explanation.Fields[0].Field)
+	assert.Equal(t, "zakat", explanation.Fields[0].Terms[0].Term)
+	assert.Equal(t, 5.0, explanation.Fields[0].Terms[0].Boost)
+}
+
+func TestMemorySearchRepository_StableScores(t *testing.T) {
+	repo, err := NewMemorySearchRepository(&config.Config{Search: config.SearchConfig{Fuzziness: 1}})
+	require.NoError(t, err)
+	require.NoError(t, repo.Index([]domain.SearchedAyah{
+		{SurahNumber: 1, AyahNumber: 1, Translation: "sabar sabir sabur sabat sadar sabak"},
+		{SurahNumber: 1, AyahNumber: 2, Translation: "sabir sabur sabur sabat sadar"},
+		{SurahNumber: 1, AyahNumber: 3, Translation: "sabar sabir sabir sabur sabak sabak sabak"},
+	}))
+
+	params := domain.SearchParams{Query: "sabar", Fields: []string{domain.FieldTranslation}, Page: 1, Limit: 10, Explain: true}
+	first, err := repo.Search(params)
+	require.NoError(t, err)
+	require.Len(t, first.Hits, 3)
+
+	// Fuzzy terms add up to the same scores, which cursors compare exactly.
+	for range 50 {
+		result, err := repo.Search(params)
+		require.NoError(t, err)
+		for i, hit := range result.Hits {
+			require.Equal(t, first.Hits[i].SortValues, hit.SortValues)
+			for j, field := range hit.Explanation.Fields {
+				require.Equal(t, first.Hits[i].Explanation.Fields[j].Score, field.Score)
+			}
+		}
+	}
+}
+
+func TestMemorySearchRepository_Reindex(t *testing.T) {
+	repo, err := NewMemorySearchRepository(&config.Config{})
+	require.NoError(t, err)
+	require.NoError(t, repo.Index(testAyahs))
+
+	updated := testAyahs[0]
+	updated.Translation = "orang yang bersabar"
+	require.NoError(t, repo.Index([]domain.SearchedAyah{updated}))
+
+	count, err := repo.GetDocCount()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(len(testAyahs)), count)
+
+	ayah, err := repo.GetDocument("2:3")
+	require.NoError(t, err)
+	require.NotNil(t, ayah)
+	assert.Equal(t, "orang yang bersabar", ayah.Translation)
+
+	ayah, err = repo.GetDocument("99:1")
+	require.NoError(t, err)
+	assert.Nil(t, ayah)
+}
+
+func TestHighlightFragment(t *testing.T) {
+	matched := func(word string) bool { return strings.EqualFold(word, "sabar") }
+
+	fragment, ok := highlightFragment("<b>Sabar</b> dan salat", matched, domain.HighlightHTML)
+	require.True(t, ok)
+	assert.Equal(t, "&lt;b&gt;<mark>Sabar</mark>&lt;/b&gt; dan salat", fragment)
+
+	long := strings.Repeat("kata ", 100) + "sabar" + strings.Repeat(" kata", 100)
+	fragment, ok = highlightFragment(long, matched, domain.HighlightPlain)
+	require.True(t, ok)
+	assert.Len(t, []rune(fragment), snippetSize)
+	assert.Contains(t, fragment, "sabar")
+
+	_, ok = highlightFragment("dan salat", matched, domain.HighlightPlain)
+	assert.False(t, ok)
+}
+
+func TestNewSearchRepositories(t *testing.T) {
+	searchRepo, tafsirRepo, err := NewSearchRepositories(&config.Config{Search: config.SearchConfig{Backend: config.SearchBackendMemory}})
+	require.NoError(t, err)
+	assert.NotNil(t, searchRepo)
+	assert.Nil(t, tafsirRepo)
+
+	_, _, err = NewSearchRepositories(&config.Config{Search: config.SearchConfig{Backend: "elastic"}})
+	assert.Error(t, err)
+}
@@ -3,29 +3,188 @@ package repository
 import (
 	"fmt"
 	"log"
+	"maps"
 	"os"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 
+	"github.com/anugrahsputra/go-quran-api/config"
 	"github.com/anugrahsputra/go-quran-api/internal/domain"
 	"github.com/blevesearch/bleve/v2"
 	_ "github.com/blevesearch/bleve/v2/analysis/analyzer/custom"
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/keyword"
 	_ "github.com/blevesearch/bleve/v2/analysis/analyzer/standard"
-	_ "github.com/blevesearch/bleve/v2/analysis/lang/ar"
+	"github.com/blevesearch/bleve/v2/analysis/lang/ar"
 	_ "github.com/blevesearch/bleve/v2/analysis/token/lowercase"
 	_ "github.com/blevesearch/bleve/v2/analysis/token/ngram"
+	_ "github.com/blevesearch/bleve/v2/analysis/tokenizer/single"
 	_ "github.com/blevesearch/bleve/v2/analysis/tokenizer/unicode"
+	"github.com/blevesearch/bleve/v2/mapping"
+	"github.com/blevesearch/bleve/v2/search"
+	"github.com/blevesearch/bleve/v2/search/query"
+)
+
+// indexMappingVersion is stored in new indexes and bumped whenever
+// createNewIndex changes in a way that needs a reindex.
+const indexMappingVersion = "4"
+
+var mappingVersionKey = []byte("mapping_version")
+
+// Gram sizes of the partial-word fields when the configuration leaves them
+// unset.
+const (
+	defaultNgramMin = 3
+	defaultNgramMax = 4
 )
 
 type quranSearchRepository struct {
-	index bleve.Index
-	path  string
+	index     *liveIndex
+	path      string
+	fuzziness int
+	// boosts are the configured field weights, overridden per search by
+	// domain.SearchParams.Boosts.
+	boosts       map[string]float64
+	scoringModel string
+	spelling     *termDictionary
+	completions  *termDictionary
+}
+
+func NewQuranSearchRepository(cfg *config.Config) (domain.QuranSearchRepository, error) {
+	if err := checkBoosts(cfg.Search.Boosts); err != nil {
+		return nil, err
+	}
+	scoringModel, err := scoringModelOrDefault(cfg.Search.ScoringModel)
+	if err != nil {
+		return nil, err
+	}
+
+	ngramMin, ngramMax := ngramSizes(cfg.Search)
+
+	create := func(indexPath string) (bleve.Index, error) {
+		return createNewIndex(indexPath, ngramMin, ngramMax)
+	}
+	opened := func(index bleve.Index) {
+		setScoringModel(index, scoringModel)
+	}
+
+	index, err := openIndex(cfg.SearchIndexPath, indexMappingVersion, create)
+	if err != nil {
+		return nil, err
+	}
+	if indexMin, indexMax, ok := ngramSize(index); ok && (indexMin != ngramMin || indexMax != ngramMax) {
+		log.Printf("Warning: Search index at %s was built with %d-%d grams instead of %d-%d. Reindex to apply SEARCH_NGRAM_MIN and SEARCH_NGRAM_MAX.",
+			cfg.SearchIndexPath, indexMin, indexMax, ngramMin, ngramMax)
+	}
+	opened(index)
+
+	return &quranSearchRepository{
+		index:        newLiveIndex(cfg.SearchIndexPath, index, create, opened),
+		path:         cfg.SearchIndexPath,
+		fuzziness:    cfg.Search.Fuzziness,
+		boosts:       cfg.Search.Boosts,
+		scoringModel: scoringModel,
+		spelling:     newTermDictionary("Translation", "Latin"),
+		completions:  newTermDictionary("Translation", "Latin", "Topic"),
+	}, nil
+}
+
+// checkBoosts rejects configured boosts for unknown fields.
+func checkBoosts(boosts map[string]float64) error {
+	for key, boost := range boosts {
+		if key != domain.BoostNgram && !slices.Contains(domain.SearchFields, key) {
+			return fmt.Errorf("invalid SEARCH_BOOSTS: unknown field %q", key)
+		}
+		if boost <= 0 {
+			return fmt.Errorf("invalid SEARCH_BOOSTS: boost for %q must be positive", key)
+		}
+	}
+	return nil
+}
+
+// ngramSizes returns the configured gram sizes of the partial-word fields,
+// falling back to the defaults when they are unset or inconsistent.
+func ngramSizes(search config.SearchConfig) (int, int) {
+	ngramMin, ngramMax := search.NgramMin, search.NgramMax
+	if ngramMin < 1 {
+		ngramMin = defaultNgramMin
+	}
+	if ngramMax < ngramMin {
+		ngramMax = max(defaultNgramMax, ngramMin)
+	}
+	return ngramMin, ngramMax
+}
+
+// scoringModelOrDefault validates a configured scoring model.
+func scoringModelOrDefault(model string) (string, error) {
+	switch model {
+	case "":
+		return domain.ScoringTFIDF, nil
+	case domain.ScoringTFIDF, domain.ScoringBM25:
+		return model, nil
+	default:
+		return "", fmt.Errorf("invalid SEARCH_SCORING_MODEL %q: must be %s or %s", model, domain.ScoringTFIDF, domain.ScoringBM25)
+	}
+}
+
+// setScoringModel switches the scoring model of an open index. bleve reads
+// it from the mapping on every search, so it needs no reindex.
+func setScoringModel(index bleve.Index, model string) {
+	if indexMapping, ok := index.Mapping().(*mapping.IndexMappingImpl); ok {
+		indexMapping.ScoringModel = model
+	}
+}
+
+// ngramSize reads the gram sizes an index was created with.
+func ngramSize(index bleve.Index) (int, int, bool) {
+	indexMapping, ok := index.Mapping().(*mapping.IndexMappingImpl)
+	if !ok {
+		return 0, 0, false
+	}
+	filter := indexMapping.CustomAnalysis.TokenFilters["ngram_filter"]
+	minSize, minOK := filter["min"].(float64)
+	maxSize, maxOK := filter["max"].(float64)
+	return int(minSize), int(maxSize), minOK && maxOK
 }
 
-func NewQuranSearchRepository(indexPath string) (domain.QuranSearchRepository, error) {
+// search runs a request with the given scoring model, or the configured one
+// when model is empty. bleve takes the scoring model from the mapping of
+// the index it searches, so another model is set on the shared mapping and
+// that search runs alone: it waits for running searches to finish and
+// holds back new ones until it is done. See
+// BenchmarkQuranSearchRepository_ScoringOverride for the cost.
+func (r *quranSearchRepository) search(request *bleve.SearchRequest, model string) (*bleve.SearchResult, error) {
+	if model == "" || model == r.scoringModel {
+		index, release := r.index.acquire()
+		defer release()
+		return index.Search(request)
+	}
+
+	index, release := r.index.acquireExclusive()
+	defer release()
+	setScoringModel(index, model)
+	defer setScoringModel(index, r.scoringModel)
+	return index.Search(request)
+}
+
+// fieldBoosts layers per-search boosts over the configured ones.
+func fieldBoosts(configured, overrides map[string]float64) map[string]float64 {
+	if len(configured) == 0 {
+		return overrides
+	}
+	boosts := maps.Clone(configured)
+	maps.Copy(boosts, overrides)
+	return boosts
+}
+
+// openIndex opens the index at indexPath, creating it when missing and
+// recreating it when it cannot be read. It warns when an existing index
+// was built with a mapping other than version.
+func openIndex(indexPath, version string, create func(string) (bleve.Index, error)) (bleve.Index, error) {
 	index, err := bleve.Open(indexPath)
 	if err == bleve.ErrorIndexPathDoesNotExist {
-		index, err = createNewIndex(indexPath)
+		index, err = create(indexPath)
 		if err != nil {
 			return nil, err
 		}
@@ -40,7 +199,7 @@ func NewQuranSearchRepository(indexPath string) (domain.QuranSearchRepository, e
 		if removeErr := os.RemoveAll(indexPath); removeErr != nil {
 			return nil, fmt.Errorf("failed to remove corrupted index at %s: %w", indexPath, removeErr)
 		}
-		index, err = createNewIndex(indexPath)
+		index, err = create(indexPath)
 		if err != nil {
 			return nil, fmt.Errorf("failed to recreate index at %s: %w", indexPath, err)
 		}
@@ -50,20 +209,61 @@ func NewQuranSearchRepository(indexPath string) (domain.QuranSearchRepository, e
 		if err != nil {
 			log.Printf("Warning: Could not get document count: %v", err)
 		} else {
-			log.Printf("Opened existing search index with %d documents", docCount)
+			log.Printf("Opened existing search index at %s with %d documents", indexPath, docCount)
+		}
+
+		if stored, _ := index.GetInternal(mappingVersionKey); string(stored) != version {
+			log.Printf("Warning: Search index at %s was built with an older mapping. Run a reindex to enable all search features.", indexPath)
 		}
 	}
 
-	return &quranSearchRepository{index: index, path: indexPath}, nil
+	return index, nil
+}
+
+// addTextAnalyzers registers the analyzers shared by the ayah and tafsir
+// indexes.
+func addTextAnalyzers(indexMapping *mapping.IndexMappingImpl) error {
+	err := indexMapping.AddCustomAnalyzer(indonesianAnalyzer, map[string]interface{}{
+		"type":          "custom",
+		"tokenizer":     "unicode",
+		"token_filters": []string{"to_lower", "stop_id", indonesianStemmer},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add indonesian analyzer: %w", err)
+	}
+
+	err = indexMapping.AddCustomAnalyzer("keyword_lower", map[string]interface{}{
+		"type":          "custom",
+		"tokenizer":     "single",
+		"token_filters": []string{"to_lower"},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to add keyword analyzer: %w", err)
+	}
+
+	return nil
+}
+
+// newIndex creates an index and records its mapping version.
+func newIndex(indexPath string, indexMapping *mapping.IndexMappingImpl, version string) (bleve.Index, error) {
+	index, err := bleve.New(indexPath, indexMapping)
+	if err != nil {
+		return nil, err
+	}
+	if err := index.SetInternal(mappingVersionKey, []byte(version)); err != nil {
+		_ = index.Close()
+		return nil, fmt.Errorf("failed to store mapping version: %w", err)
+	}
+	return index, nil
 }
 
-func createNewIndex(indexPath string) (bleve.Index, error) {
+func createNewIndex(indexPath string, ngramMin, ngramMax int) (bleve.Index, error) {
 	mapping := bleve.NewIndexMapping()
 
 	err := mapping.AddCustomTokenFilter("ngram_filter", map[string]interface{}{
 		"type": "ngram",
-		"min":  3.0,
-		"max":  4.0,
+		"min":  float64(ngramMin),
+		"max":  float64(ngramMax),
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to add ngram filter: %w", err)
@@ -78,6 +278,10 @@ func createNewIndex(indexPath string) (bleve.Index, error) {
 		return nil, fmt.Errorf("failed to add ngram analyzer: %w", err)
 	}
 
+	if err := addTextAnalyzers(mapping); err != nil {
+		return nil, err
+	}
+
 	ayahMapping := bleve.NewDocumentMapping()
 
 	surahNumberFieldMapping := bleve.NewNumericFieldMapping()
@@ -88,6 +292,19 @@ func createNewIndex(indexPath string) (bleve.Index, error) {
 	ayahNumberFieldMapping.Store = true
 	ayahMapping.AddFieldMappingsAt("AyahNumber", ayahNumberFieldMapping)
 
+	juzFieldMapping := bleve.NewNumericFieldMapping()
+	juzFieldMapping.Store = true
+	ayahMapping.AddFieldMappingsAt("Juz", juzFieldMapping)
+
+	pageFieldMapping := bleve.NewNumericFieldMapping()
+	pageFieldMapping.Store = true
+	ayahMapping.AddFieldMappingsAt("Page", pageFieldMapping)
+
+	locationFieldMapping := bleve.NewTextFieldMapping()
+	locationFieldMapping.Store = true
+	locationFieldMapping.Analyzer = "keyword_lower"
+	ayahMapping.AddFieldMappingsAt("Location", locationFieldMapping)
+
 	textFieldMapping := bleve.NewTextFieldMapping()
 	textFieldMapping.Store = true
 	textFieldMapping.Analyzer = "standard"
@@ -113,28 +330,81 @@ func createNewIndex(indexPath string) (bleve.Index, error) {
 	transNgram.Analyzer = "ngram_analyzer"
 	ayahMapping.AddFieldMappingsAt("Translation_ngram", transNgram)
 
+	transStem := bleve.NewTextFieldMapping()
+	transStem.Store = false
+	transStem.Analyzer = indonesianAnalyzer
+	ayahMapping.AddFieldMappingsAt("Translation_stem", transStem)
+
 	tafsirStd := bleve.NewTextFieldMapping()
 	tafsirStd.Store = true
 	tafsirStd.Analyzer = "standard"
 	ayahMapping.AddFieldMappingsAt("Tafsir", tafsirStd)
 
+	tafsirStem := bleve.NewTextFieldMapping()
+	tafsirStem.Store = false
+	tafsirStem.Analyzer = indonesianAnalyzer
+	ayahMapping.AddFieldMappingsAt("Tafsir_stem", tafsirStem)
+
+	// The shorter tafsir sections are searchable on their own, so a query
+	// can target the section it is about.
+	for _, section := range []string{"Wajiz", "SababNuzul", "Conclusion"} {
+		sectionStd := bleve.NewTextFieldMapping()
+		sectionStd.Store = true
+		sectionStd.Analyzer = "standard"
+		ayahMapping.AddFieldMappingsAt(section, sectionStd)
+
+		sectionStem := bleve.NewTextFieldMapping()
+		sectionStem.Store = false
+		sectionStem.Analyzer = indonesianAnalyzer
+		ayahMapping.AddFieldMappingsAt(section+"_stem", sectionStem)
+	}
+
+	kosakata := bleve.NewTextFieldMapping()
+	kosakata.Store = true
+	kosakata.Analyzer = "standard"
+	ayahMapping.AddFieldMappingsAt("Kosakata", kosakata)
+
+	kitabah := bleve.NewTextFieldMapping()
+	kitabah.Store = true
+	kitabah.Analyzer = ar.AnalyzerName
+	ayahMapping.AddFieldMappingsAt("Kitabah", kitabah)
+
+	surahName := bleve.NewTextFieldMapping()
+	surahName.Store = true
+	surahName.Analyzer = "standard"
+	ayahMapping.AddFieldMappingsAt("SurahName", surahName)
+
+	translationsMapping := bleve.NewDocumentMapping()
+	translationsMapping.DefaultAnalyzer = "standard"
+	ayahMapping.AddSubDocumentMapping("Translations", translationsMapping)
+
 	topicStd := bleve.NewTextFieldMapping()
 	topicStd.Store = true
 	topicStd.Analyzer = "standard"
 	ayahMapping.AddFieldMappingsAt("Topic", topicStd)
 
+	topicFacet := bleve.NewTextFieldMapping()
+	topicFacet.Store = false
+	topicFacet.IncludeTermVectors = false
+	topicFacet.IncludeInAll = false
+	topicFacet.Analyzer = keyword.Name
+	ayahMapping.AddFieldMappingsAt("Topic_facet", topicFacet)
+
 	mapping.DefaultAnalyzer = "standard"
 	mapping.DefaultMapping = ayahMapping
 
-	return bleve.New(indexPath, mapping)
+	return newIndex(indexPath, mapping, indexMappingVersion)
 }
 
 func (r *quranSearchRepository) Index(ayahs []domain.SearchedAyah) error {
-	batch := r.index.NewBatch()
+	index, release := r.index.acquireTarget()
+	defer release()
+
+	batch := index.NewBatch()
 	indexedCount := 0
 
 	for _, ayah := range ayahs {
-		id := strconv.Itoa(ayah.SurahNumber) + ":" + strconv.Itoa(ayah.AyahNumber)
+		id := domain.VerseRef{Surah: ayah.SurahNumber, Ayah: ayah.AyahNumber}.String()
 
 		doc := map[string]any{
 			"SurahNumber":       ayah.SurahNumber,
@@ -144,8 +414,31 @@ func (r *quranSearchRepository) Index(ayahs []domain.SearchedAyah) error {
 			"Latin_ngram":       ayah.Latin,
 			"Translation":       ayah.Translation,
 			"Translation_ngram": ayah.Translation,
+			"Translation_stem":  ayah.Translation,
 			"Tafsir":            ayah.Tafsir,
+			"Tafsir_stem":       ayah.Tafsir,
+			"Wajiz":             ayah.Wajiz,
+			"Wajiz_stem":        ayah.Wajiz,
+			"SababNuzul":        ayah.SababNuzul,
+			"SababNuzul_stem":   ayah.SababNuzul,
+			"Kosakata":          ayah.Kosakata,
+			"Conclusion":        ayah.Conclusion,
+			"Conclusion_stem":   ayah.Conclusion,
+			"Kitabah":           ayah.Kitabah,
+			"SurahName":         ayah.SurahName,
 			"Topic":             ayah.Topic,
+			"Topic_facet":       ayah.Topic,
+			"Juz":               ayah.Juz,
+			"Page":              ayah.Page,
+			"Location":          ayah.Location,
+		}
+
+		if len(ayah.Translations) > 0 {
+			translations := make(map[string]any, len(ayah.Translations))
+			for translationID, text := range ayah.Translations {
+				translations[translationKey(translationID)] = text
+			}
+			doc["Translations"] = translations
 		}
 
 		if err := batch.Index(id, doc); err != nil {
@@ -155,85 +448,612 @@ func (r *quranSearchRepository) Index(ayahs []domain.SearchedAyah) error {
 	}
 
 	log.Printf("Indexed %d ayahs to %s", indexedCount, r.path)
-	return r.index.Batch(batch)
+	defer r.spelling.invalidate()
+	defer r.completions.invalidate()
+	return index.Batch(batch)
 }
 
-func (r *quranSearchRepository) Search(query string, page, limit int) (*bleve.SearchResult, error) {
-	if page < 1 {
-		page = 1
+// Rebuild directs Index to a new index built with the current mapping and
+// gram sizes, replacing the searched one when the returned function is
+// called with true.
+func (r *quranSearchRepository) Rebuild() (func(keep bool) error, error) {
+	finish, err := r.index.rebuild()
+	if err != nil {
+		return nil, err
+	}
+	return func(keep bool) error {
+		defer r.spelling.invalidate()
+		defer r.completions.invalidate()
+		return finish(keep)
+	}, nil
+}
+
+func (r *quranSearchRepository) Search(params domain.SearchParams) (*domain.IndexResults, error) {
+	from, size := params.Window()
+
+	fuzziness := r.fuzziness
+	if params.Fuzziness != nil {
+		fuzziness = *params.Fuzziness
+	}
+	params.Boosts = fieldBoosts(r.boosts, params.Boosts)
+
+	searchQuery := buildTextQuery(params, fuzziness)
+	if filters := buildFilterQueries(params.Filter); len(filters) > 0 {
+		searchQuery = bleve.NewConjunctionQuery(append([]query.Query{searchQuery}, filters...)...)
+	}
+
+	searchRequest := bleve.NewSearchRequest(searchQuery)
+	translations := searchedTranslations(params)
+	searchRequest.Fields = []string{"SurahNumber", "AyahNumber", "Text", "Latin", "Kitabah", "SurahName", "Topic", "Juz", "Page", "Location"}
+	for _, translation := range translations {
+		searchRequest.Fields = append(searchRequest.Fields, translation.field)
 	}
-	if limit < 1 {
-		limit = 10
+	if !params.OmitTafsir {
+		searchRequest.Fields = append(searchRequest.Fields, "Tafsir", "Wajiz", "SababNuzul", "Kosakata", "Conclusion")
 	}
-	if limit > 100 {
-		limit = 100
+	searchRequest.Size = size
+	searchRequest.From = from
+	searchRequest.Explain = params.Explain
+	// Locations tell which translation a hit matched.
+	searchRequest.IncludeLocations = len(translations) > 1
+
+	switch params.Highlight {
+	case domain.HighlightHTML:
+		searchRequest.Highlight = bleve.NewHighlightWithStyle(htmlHighlighter)
+	case domain.HighlightPlain:
+		searchRequest.Highlight = bleve.NewHighlightWithStyle(plainTextHighlighter)
 	}
 
-	queryLower := query
+	// Sorting always ends in mushaf order, so every hit has a distinct
+	// sort key to continue after.
+	sortFields := params.Sort
+	if len(sortFields) == 0 {
+		sortFields = []domain.SortField{{Field: domain.SortScore, Desc: true}}
+	}
+	sortOrder := buildSortOrder(sortFields)
+	searchRequest.SortByCustom(sortOrder)
+	if len(params.SearchAfter) > 0 {
+		searchRequest.SetSearchAfter(params.SearchAfter)
+	}
 
-	translationMatch := bleve.NewMatchQuery(queryLower)
-	translationMatch.SetField("Translation")
-	translationMatch.SetBoost(5.0)
+	for _, facet := range params.Facets {
+		if facetRequest := buildFacetRequest(facet, params.FacetSize); facetRequest != nil {
+			searchRequest.AddFacet(facet, facetRequest)
+		}
+	}
 
-	tafsirMatch := bleve.NewMatchQuery(queryLower)
-	tafsirMatch.SetField("Tafsir")
-	tafsirMatch.SetBoost(3.0)
+	result, err := r.search(searchRequest, params.ScoringModel)
+	if err != nil {
+		return nil, err
+	}
 
-	topicMatch := bleve.NewMatchQuery(queryLower)
-	topicMatch.SetField("Topic")
-	topicMatch.SetBoost(4.0)
+	for _, hit := range result.Hits {
+		selectTranslation(hit, translations)
+		scoreSortValues(hit, sortOrder)
+	}
 
-	latinMatch := bleve.NewMatchQuery(queryLower)
-	latinMatch.SetField("Latin")
-	latinMatch.SetBoost(5.0)
+	return indexResults(result, params.Explain), nil
+}
 
-	translationNgram := bleve.NewMatchQuery(queryLower)
-	translationNgram.SetField("Translation_ngram")
-	translationNgram.SetBoost(1.0)
+// scoreSortValues replaces the placeholder bleve decodes score sort values
+// to with the score itself, so that DecodedSort can be passed back as
+// domain.SearchParams.SearchAfter.
+func scoreSortValues(hit *search.DocumentMatch, order search.SortOrder) {
+	for i, sort := range order {
+		if _, ok := sort.(*search.SortScore); ok && i < len(hit.DecodedSort) {
+			hit.DecodedSort[i] = strconv.FormatFloat(hit.Score, 'g', -1, 64)
+		}
+	}
+}
 
-	latinNgram := bleve.NewMatchQuery(queryLower)
-	latinNgram.SetField("Latin_ngram")
-	latinNgram.SetBoost(1.0)
+// searchField describes how a domain.SearchFields entry is queried.
+type searchField struct {
+	name  string
+	boost float64
+	// ngram is the companion partial-word field matched by optional terms.
+	ngram string
+	// stem is the companion field analyzed with the Indonesian stemmer,
+	// matched by words but not phrases.
+	stem  string
+	fuzzy bool
+	// ngramBoost scales the ngram match, from the domain.BoostNgram boost.
+	ngramBoost float64
+	// numeric is the numeric field matched when the clause is a number or
+	// a range such as "1-3". Fields without a name only match numbers.
+	numeric string
+	// matchAll requires every word of the clause, for short fields such
+	// as surah names where any one word matches too broadly.
+	matchAll bool
+	// weight is the translation weight already applied to boost, which
+	// also scales the ngram match.
+	weight float64
+}
+
+var searchFields = map[string]searchField{
+	domain.FieldTranslation: {name: "Translation", boost: 5.0, ngram: "Translation_ngram", stem: "Translation_stem", fuzzy: true},
+	domain.FieldLatin:       {name: "Latin", boost: 5.0, ngram: "Latin_ngram", fuzzy: true},
+	domain.FieldTafsir:      {name: "Tafsir", boost: 3.0, stem: "Tafsir_stem"},
+	domain.FieldTopic:       {name: "Topic", boost: 4.0},
+	domain.FieldWajiz:       {name: "Wajiz", boost: 3.0, stem: "Wajiz_stem"},
+	domain.FieldSababNuzul:  {name: "SababNuzul", boost: 2.5, stem: "SababNuzul_stem"},
+	domain.FieldKosakata:    {name: "Kosakata", boost: 1.5},
+	domain.FieldConclusion:  {name: "Conclusion", boost: 2.0, stem: "Conclusion_stem"},
+	domain.FieldKitabah:     {name: "Kitabah", boost: 5.0},
+	domain.FieldSurah:       {name: "SurahName", boost: 4.0, numeric: "SurahNumber", matchAll: true},
+	domain.FieldJuz:         {boost: 1.0, numeric: "Juz"},
+	domain.FieldPage:        {boost: 1.0, numeric: "Page"},
+}
+
+// stemWeight scales the boost of stemmed matches so that the exact word
+// form ranks above other words sharing its stem.
+const stemWeight = 0.6
+
+// buildTextQuery turns the query clauses into a boolean query. Required and
+// excluded clauses match whole words only; optional clauses also match
+// partial words through the ngram fields.
+func buildTextQuery(params domain.SearchParams, fuzziness int) query.Query {
+	clauses := params.Clauses
+	if len(clauses) == 0 {
+		clauses = []domain.QueryClause{{Text: params.Query, Occur: domain.OccurShould}}
+	}
+
+	fields := params.Fields
+	if len(fields) == 0 {
+		fields = domain.DefaultSearchFields
+	}
+
+	booleanQuery := bleve.NewBooleanQuery()
+	hasMust := false
+	for _, clause := range clauses {
+		clauseFields := fields
+		if clause.Field != "" {
+			clauseFields = []string{clause.Field}
+		}
+
+		switch clause.Occur {
+		case domain.OccurMust:
+			booleanQuery.AddMust(clauseQuery(clause, clauseFields, params, fuzziness))
+			hasMust = true
+		case domain.OccurMustNot:
+			booleanQuery.AddMustNot(clauseQuery(clause, clauseFields, params, 0))
+		default:
+			booleanQuery.AddShould(clauseQuery(clause, clauseFields, params, fuzziness))
+		}
+	}
+	if !hasMust && booleanQuery.Should != nil {
+		booleanQuery.SetMinShould(1)
+	}
+	if len(params.Exclude) > 0 {
+		booleanQuery.AddMustNot(bleve.NewDocIDQuery(params.Exclude))
+	}
 
-	disjunctionQuery := bleve.NewDisjunctionQuery(
-		translationMatch,
-		tafsirMatch,
-		topicMatch,
-		latinMatch,
-		translationNgram,
-		latinNgram,
-	)
+	return booleanQuery
+}
+
+// lookupSearchFields resolves a domain.SearchFields key for a search,
+// applying boost overrides and the searched translations. Only the
+// translation key can resolve to more than one field.
+func lookupSearchFields(key string, params domain.SearchParams) []searchField {
+	field, ok := searchFields[key]
+	if !ok {
+		return nil
+	}
+	if boost, ok := params.Boosts[key]; ok {
+		field.boost = boost
+	}
+	field.ngramBoost = 1.0
+	if boost, ok := params.Boosts[domain.BoostNgram]; ok {
+		field.ngramBoost = boost
+	}
+	field.weight = 1.0
+	if key != domain.FieldTranslation {
+		return []searchField{field}
+	}
+
+	translations := searchedTranslations(params)
+	fields := make([]searchField, 0, len(translations))
+	for _, translation := range translations {
+		translationField := field
+		translationField.boost *= translation.weight
+		translationField.weight = translation.weight
+		if translation.id != domain.DefaultTranslation {
+			translationField.name, translationField.ngram, translationField.stem = translation.field, "", ""
+		}
+		fields = append(fields, translationField)
+	}
+	return fields
+}
+
+type searchedTranslation struct {
+	id     string
+	field  string
+	weight float64
+}
+
+// searchedTranslations lists the translations a search matches, highest
+// weight first.
+func searchedTranslations(params domain.SearchParams) []searchedTranslation {
+	if params.Translation != "" || len(params.TranslationBoosts) == 0 {
+		id := params.Translation
+		if id == "" {
+			id = domain.DefaultTranslation
+		}
+		return []searchedTranslation{{id: id, field: translationFieldName(id), weight: 1.0}}
+	}
+
+	translations := make([]searchedTranslation, 0, len(params.TranslationBoosts))
+	for id, weight := range params.TranslationBoosts {
+		translations = append(translations, searchedTranslation{id: id, field: translationFieldName(id), weight: weight})
+	}
+	sort.Slice(translations, func(i, j int) bool {
+		if translations[i].weight != translations[j].weight {
+			return translations[i].weight > translations[j].weight
+		}
+		return translations[i].id < translations[j].id
+	})
+	return translations
+}
+
+// selectTranslation moves the text and fragments of the translation that
+// best matched a hit to Translation, drops the other translations and
+// records the chosen ID as MatchedTranslation. Hits matching no translation
+// return the highest weighted one.
+func selectTranslation(hit *search.DocumentMatch, translations []searchedTranslation) {
+	best, bestScore := translations[0], 0.0
+	if len(translations) > 1 {
+		for _, translation := range translations {
+			score := float64(len(hit.Locations[translation.field]))
+			if translation.id == domain.DefaultTranslation && score == 0 {
+				// Partial and stemmed matches count as half a term.
+				defaultField := searchFields[domain.FieldTranslation]
+				if len(hit.Locations[defaultField.ngram]) > 0 || len(hit.Locations[defaultField.stem]) > 0 {
+					score = 0.5
+				}
+			}
+			if score*translation.weight > bestScore {
+				best, bestScore = translation, score*translation.weight
+			}
+		}
+		hit.Locations = nil
+	}
+
+	text, fragments := hit.Fields[best.field], hit.Fragments[best.field]
+	for _, translation := range translations {
+		delete(hit.Fields, translation.field)
+		delete(hit.Fragments, translation.field)
+	}
+	// Callers always read the selected translation from Translation.
+	hit.Fields["Translation"] = text
+	if fragments != nil {
+		hit.Fragments["Translation"] = fragments
+	}
+	hit.Fields["MatchedTranslation"] = best.id
+}
+
+// translationFieldName returns the index field holding a translation.
+func translationFieldName(id string) string {
+	if name := translationField(id); name != "" {
+		return name
+	}
+	return searchFields[domain.FieldTranslation].name
+}
+
+// translationField returns the index field holding a translation other
+// than domain.DefaultTranslation, or "" for the default translation.
+func translationField(id string) string {
+	if id == "" || id == domain.DefaultTranslation {
+		return ""
+	}
+	return "Translations." + translationKey(id)
+}
+
+// translationKey turns a translation ID into a field name without dots,
+// which bleve reads as path separators.
+func translationKey(id string) string {
+	return strings.ReplaceAll(id, ".", "_")
+}
+
+// synonymWeight scales the boost of clause synonyms so that the spelling
+// the user typed ranks first.
+const synonymWeight = 0.8
+
+func clauseQuery(clause domain.QueryClause, fields []string, params domain.SearchParams, fuzziness int) query.Query {
+	clauseBoost := clause.Boost
+	if clauseBoost <= 0 {
+		clauseBoost = 1.0
+	}
+
+	var queries []query.Query
+	for _, key := range fields {
+		for _, field := range lookupSearchFields(key, params) {
+			queries = append(queries, fieldQueries(clause, field, clauseBoost, fuzziness)...)
+		}
+	}
+
+	if len(queries) == 0 {
+		// Only numeric fields were targeted and the clause is not a number.
+		return bleve.NewMatchNoneQuery()
+	}
+
+	disjunctionQuery := bleve.NewDisjunctionQuery(queries...)
 	disjunctionQuery.SetMin(1)
+	return disjunctionQuery
+}
 
-	offset := (page - 1) * limit
+func fieldQueries(clause domain.QueryClause, field searchField, clauseBoost float64, fuzziness int) []query.Query {
+	var queries []query.Query
+	field.boost *= clauseBoost
 
-	searchRequest := bleve.NewSearchRequest(disjunctionQuery)
-	searchRequest.Fields = []string{"SurahNumber", "AyahNumber", "Text", "Latin", "Translation", "Tafsir", "Topic"}
-	searchRequest.Size = limit
-	searchRequest.From = offset
-	searchRequest.IncludeLocations = false
+	if field.numeric != "" {
+		if lower, upper, ok := parseNumberRange(clause.Text); ok {
+			numericQuery := numericBetween(field.numeric, lower, upper)
+			numericQuery.SetBoost(field.boost)
+			return []query.Query{numericQuery}
+		}
+	}
+	if field.name == "" {
+		return nil
+	}
+
+	if clause.Phrase {
+		queries = append(queries, phraseQuery(clause.Text, field.name, field.boost))
+	} else {
+		matchQuery := bleve.NewMatchQuery(clause.Text)
+		matchQuery.SetField(field.name)
+		matchQuery.SetBoost(field.boost)
+		if field.fuzzy {
+			matchQuery.SetFuzziness(fuzziness)
+		}
+		if field.matchAll {
+			matchQuery.SetOperator(query.MatchQueryOperatorAnd)
+		}
+		queries = append(queries, matchQuery)
+
+		if field.stem != "" {
+			stemQuery := bleve.NewMatchQuery(clause.Text)
+			stemQuery.SetField(field.stem)
+			stemQuery.SetBoost(field.boost * stemWeight)
+			queries = append(queries, stemQuery)
+		}
+
+		if field.ngram != "" && clause.Occur == domain.OccurShould {
+			ngramQuery := bleve.NewMatchQuery(clause.Text)
+			ngramQuery.SetField(field.ngram)
+			ngramQuery.SetBoost(clauseBoost * field.weight * field.ngramBoost)
+			queries = append(queries, ngramQuery)
+		}
+	}
+
+	for _, synonym := range clause.Synonyms {
+		if strings.Contains(synonym, " ") {
+			queries = append(queries, phraseQuery(synonym, field.name, field.boost*synonymWeight))
+			continue
+		}
+		synonymQuery := bleve.NewMatchQuery(synonym)
+		synonymQuery.SetField(field.name)
+		synonymQuery.SetBoost(field.boost * synonymWeight)
+		queries = append(queries, synonymQuery)
+	}
+
+	return queries
+}
 
-	result, err := r.index.Search(searchRequest)
+// parseNumberRange reads a number such as "3" or an inclusive range such as
+// "1-3".
+func parseNumberRange(text string) (lower, upper int, ok bool) {
+	from, to, isRange := strings.Cut(strings.TrimSpace(text), "-")
+	lower, err := strconv.Atoi(strings.TrimSpace(from))
 	if err != nil {
-		return nil, err
+		return 0, 0, false
+	}
+	if !isRange {
+		return lower, lower, true
+	}
+	upper, err = strconv.Atoi(strings.TrimSpace(to))
+	if err != nil || upper < lower {
+		return 0, 0, false
+	}
+	return lower, upper, true
+}
+
+func phraseQuery(text, field string, boost float64) query.Query {
+	phraseQuery := bleve.NewMatchPhraseQuery(text)
+	phraseQuery.SetField(field)
+	phraseQuery.SetBoost(boost)
+	return phraseQuery
+}
+
+// buildSortOrder translates the requested sort into bleve sort fields,
+// breaking ties in mushaf order.
+func buildSortOrder(fields []domain.SortField) search.SortOrder {
+	var order search.SortOrder
+	for _, field := range fields {
+		switch field.Field {
+		case domain.SortScore:
+			order = append(order, &search.SortScore{Desc: field.Desc})
+		case domain.SortSurah:
+			order = append(order, numericSort("SurahNumber", field.Desc), numericSort("AyahNumber", field.Desc))
+		case domain.SortSurahNumber:
+			order = append(order, numericSort("SurahNumber", field.Desc))
+		case domain.SortJuz:
+			order = append(order, numericSort("Juz", field.Desc))
+		case domain.SortPage:
+			order = append(order, numericSort("Page", field.Desc))
+		}
 	}
+	return append(order, numericSort("SurahNumber", false), numericSort("AyahNumber", false))
+}
+
+func numericSort(field string, desc bool) *search.SortField {
+	return &search.SortField{Field: field, Type: search.SortFieldAsNumber, Desc: desc}
+}
 
-	if result.Total == 0 {
-		log.Printf("Search '%s' returned 0 results", query)
+func buildFacetRequest(facet string, size int) *bleve.FacetRequest {
+	if size < 1 {
+		size = 10
 	}
 
-	return result, nil
+	switch facet {
+	case domain.FacetSurah:
+		return numericValueFacet("SurahNumber", size, 114)
+	case domain.FacetJuz:
+		return numericValueFacet("Juz", size, 30)
+	case domain.FacetTopic:
+		return bleve.NewFacetRequest("Topic_facet", size)
+	}
+	return nil
 }
 
-func (r *quranSearchRepository) GetDocument(id string) (map[string]any, error) {
-	return nil, nil
+// numericValueFacet counts hits per integer value of a numeric field by
+// giving every value from 1 to maxValue its own range.
+func numericValueFacet(field string, size, maxValue int) *bleve.FacetRequest {
+	facetRequest := bleve.NewFacetRequest(field, size)
+	for value := 1; value <= maxValue; value++ {
+		lower, upper := float64(value), float64(value+1)
+		facetRequest.AddNumericRange(strconv.Itoa(value), &lower, &upper)
+	}
+	return facetRequest
+}
+
+func buildFilterQueries(filter domain.SearchFilter) []query.Query {
+	var filters []query.Query
+
+	if filter.Surah > 0 {
+		filters = append(filters, numericEquals("SurahNumber", filter.Surah))
+	}
+	if filter.Juz > 0 {
+		filters = append(filters, numericEquals("Juz", filter.Juz))
+	}
+	if filter.Location != "" {
+		locationQuery := bleve.NewTermQuery(strings.ToLower(filter.Location))
+		locationQuery.SetField("Location")
+		filters = append(filters, locationQuery)
+	}
+	if filter.From != nil || filter.To != nil {
+		filters = append(filters, verseRangeQuery(filter.From, filter.To))
+	}
+
+	return filters
+}
+
+// verseRangeQuery matches every ayah between from and to inclusive, in
+// mushaf order. A nil bound leaves that side of the range open.
+func verseRangeQuery(from, to *domain.VerseRef) query.Query {
+	start := domain.VerseRef{Surah: 1, Ayah: 1}
+	if from != nil {
+		start = *from
+	}
+	end := domain.VerseRef{Surah: 114, Ayah: 286}
+	if to != nil {
+		end = *to
+	}
+
+	if start.Surah == end.Surah {
+		return bleve.NewConjunctionQuery(
+			numericEquals("SurahNumber", start.Surah),
+			numericBetween("AyahNumber", start.Ayah, end.Ayah),
+		)
+	}
+
+	ranges := []query.Query{
+		bleve.NewConjunctionQuery(
+			numericEquals("SurahNumber", start.Surah),
+			numericBetween("AyahNumber", start.Ayah, 286),
+		),
+		bleve.NewConjunctionQuery(
+			numericEquals("SurahNumber", end.Surah),
+			numericBetween("AyahNumber", 1, end.Ayah),
+		),
+	}
+	if end.Surah-start.Surah > 1 {
+		ranges = append(ranges, numericBetween("SurahNumber", start.Surah+1, end.Surah-1))
+	}
+
+	return bleve.NewDisjunctionQuery(ranges...)
+}
+
+func numericEquals(field string, value int) query.Query {
+	return numericBetween(field, value, value)
+}
+
+func numericBetween(field string, lower, upper int) *query.NumericRangeQuery {
+	minValue, maxValue := float64(lower), float64(upper)
+	inclusive := true
+	rangeQuery := bleve.NewNumericRangeInclusiveQuery(&minValue, &maxValue, &inclusive, &inclusive)
+	rangeQuery.SetField(field)
+	return rangeQuery
+}
+
+// SpellingSuggestions proposes corrected versions of query by replacing
+// words missing from the Translation and Latin term dictionaries with their
+// closest known terms.
+func (r *quranSearchRepository) SpellingSuggestions(query string, limit int) ([]string, error) {
+	index, release := r.index.acquire()
+	defer release()
+	if err := r.spelling.load(index); err != nil {
+		return nil, fmt.Errorf("failed to load term dictionary: %w", err)
+	}
+
+	return r.spelling.suggestCorrections(query, limit), nil
+}
+
+// Complete returns indexed Translation, Latin and Topic terms starting with
+// prefix, most frequent first.
+func (r *quranSearchRepository) Complete(prefix string, limit int) ([]domain.Completion, error) {
+	index, release := r.index.acquire()
+	defer release()
+	if err := r.completions.load(index); err != nil {
+		return nil, fmt.Errorf("failed to load term dictionary: %w", err)
+	}
+
+	return r.completions.complete(prefix, limit), nil
+}
+
+func (r *quranSearchRepository) GetDocument(id string) (*domain.SearchedAyah, error) {
+	fields, err := r.document(id)
+	if err != nil || fields == nil {
+		return nil, err
+	}
+	ayah := fieldsToAyah(fields)
+	return &ayah, nil
+}
+
+// document returns the stored fields of a document, or nil when it is not
+// indexed.
+func (r *quranSearchRepository) document(id string) (map[string]any, error) {
+	index, release := r.index.acquire()
+	defer release()
+	return storedFields(index, id)
+}
+
+func storedFields(index bleve.Index, id string) (map[string]any, error) {
+	searchRequest := bleve.NewSearchRequest(bleve.NewDocIDQuery([]string{id}))
+	searchRequest.Fields = []string{"*"}
+	searchRequest.Size = 1
+
+	result, err := index.Search(searchRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(result.Hits) == 0 {
+		return nil, nil
+	}
+
+	return result.Hits[0].Fields, nil
 }
 
 func (r *quranSearchRepository) GetDocCount() (uint64, error) {
-	return r.index.DocCount()
+	index, release := r.index.acquire()
+	defer release()
+	return index.DocCount()
 }
 
 func (r *quranSearchRepository) IsHealthy() bool {
-	_, err := r.index.DocCount()
+	_, err := r.GetDocCount()
 	return err == nil
 }
+
+func (r *quranSearchRepository) Close() error {
+	return r.index.close()
+}
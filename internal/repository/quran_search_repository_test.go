@@ -0,0 +1,513 @@
+package repository
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/anugrahsputra/go-quran-api/config"
+	"github.com/anugrahsputra/go-quran-api/internal/domain"
+	"github.com/blevesearch/bleve/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testAyahs = []domain.SearchedAyah{
+	{SurahNumber: 2, AyahNumber: 3, Translation: "orang yang beriman kepada yang gaib", Topic: "Sifat orang bertakwa", Juz: 1, Page: 2, Location: domain.LocationMadaniyah},
+	{SurahNumber: 2, AyahNumber: 43, Translation: "dan laksanakanlah salat, tunaikanlah zakat", Tafsir: "perintah menegakkan salat dan zakat", Juz: 1, Page: 7, Location: domain.LocationMadaniyah},
+	{SurahNumber: 2, AyahNumber: 255, Translation: "Allah, tidak ada tuhan selain Dia", Translations: map[string]string{"en.sahih": "Allah - there is no deity except Him, the Ever-Living"}, Juz: 3, Page: 42, Location: domain.LocationMadaniyah},
+	{SurahNumber: 3, AyahNumber: 102, Translation: "wahai orang-orang yang beriman, bertakwalah", SababNuzul: "pertikaian kaum Aus dan Khazraj", SurahName: "Ali 'Imran", Translations: map[string]string{"en.sahih": "O you who have believed, fear Allah"}, Topic: "Sifat orang bertakwa", Juz: 4, Page: 63, Location: domain.LocationMadaniyah},
+	{SurahNumber: 10, AyahNumber: 9, Translation: "orang yang beriman dan mengerjakan kebajikan", SurahName: "Yunus", Juz: 11, Page: 209, Location: domain.LocationMakkiyah},
+}
+
+// searchBackends are the QuranSearchRepository implementations, which are
+// expected to find the same verses.
+var searchBackends = []struct {
+	name string
+	new  func(cfg *config.Config) (domain.QuranSearchRepository, error)
+}{
+	{"bleve", NewQuranSearchRepository},
+	{"memory", NewMemorySearchRepository},
+}
+
+// forEachBackend runs test against each backend, indexed with testAyahs.
+func forEachBackend(t *testing.T, test func(t *testing.T, repo domain.QuranSearchRepository)) {
+	for _, backend := range searchBackends {
+		t.Run(backend.name, func(t *testing.T) {
+			repo, err := backend.new(&config.Config{
+				SearchIndexPath: filepath.Join(t.TempDir(), "quran.bleve"),
+			})
+			require.NoError(t, err)
+			require.NoError(t, repo.Index(testAyahs))
+
+			test(t, repo)
+		})
+	}
+}
+
+func hitID(hit domain.IndexHit) string {
+	return domain.VerseRef{Surah: hit.SurahNumber, Ayah: hit.AyahNumber}.String()
+}
+
+func searchIDs(t *testing.T, repo domain.QuranSearchRepository, params domain.SearchParams) []string {
+	t.Helper()
+
+	result, err := repo.Search(params)
+	require.NoError(t, err)
+
+	ids := make([]string, 0, len(result.Hits))
+	for _, hit := range result.Hits {
+		ids = append(ids, hitID(hit))
+	}
+	return ids
+}
+
+func TestQuranSearchRepository_SearchFilters(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repo domain.QuranSearchRepository) {
+		tests := []struct {
+			name   string
+			filter domain.SearchFilter
+			want   []string
+		}{
+			{"no filter", domain.SearchFilter{}, []string{"2:3", "3:102", "10:9"}},
+			{"surah", domain.SearchFilter{Surah: 3}, []string{"3:102"}},
+			{"juz", domain.SearchFilter{Juz: 11}, []string{"10:9"}},
+			{"location", domain.SearchFilter{Location: domain.LocationMakkiyah}, []string{"10:9"}},
+			{"range within surah", domain.SearchFilter{
+				From: &domain.VerseRef{Surah: 2, Ayah: 1},
+				To:   &domain.VerseRef{Surah: 2, Ayah: 100},
+			}, []string{"2:3"}},
+			{"range across surahs", domain.SearchFilter{
+				From: &domain.VerseRef{Surah: 2, Ayah: 100},
+				To:   &domain.VerseRef{Surah: 10, Ayah: 9},
+			}, []string{"3:102", "10:9"}},
+			{"open ended range", domain.SearchFilter{
+				From: &domain.VerseRef{Surah: 3, Ayah: 1},
+			}, []string{"3:102", "10:9"}},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				ids := searchIDs(t, repo, domain.SearchParams{Query: "beriman", Page: 1, Limit: 10, Filter: tt.filter})
+				assert.ElementsMatch(t, tt.want, ids)
+			})
+		}
+	})
+}
+
+func TestQuranSearchRepository_SearchPinned(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repo domain.QuranSearchRepository) {
+		params := domain.SearchParams{Query: "beriman", Limit: 2, Sort: []domain.SortField{{Field: domain.SortSurah}}}
+
+		params.Page, params.Exclude = 1, []string{"3:102"}
+		assert.Equal(t, []string{"2:3", "10:9"}, searchIDs(t, repo, params))
+
+		params.Exclude, params.Pinned = nil, 1
+		assert.Equal(t, []string{"2:3"}, searchIDs(t, repo, params))
+		params.Page = 2
+		assert.Equal(t, []string{"3:102", "10:9"}, searchIDs(t, repo, params))
+	})
+}
+
+func TestQuranSearchRepository_Rebuild(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repo domain.QuranSearchRepository) {
+		finish, err := repo.Rebuild()
+		require.NoError(t, err)
+		require.NoError(t, repo.Index(testAyahs[:2]))
+
+		count, err := repo.GetDocCount()
+		require.NoError(t, err)
+		assert.Equal(t, uint64(len(testAyahs)), count, "searches use the current index until the rebuild finishes")
+
+		require.NoError(t, finish(true))
+		count, err = repo.GetDocCount()
+		require.NoError(t, err)
+		assert.Equal(t, uint64(2), count)
+		ayah, err := repo.GetDocument("10:9")
+		require.NoError(t, err)
+		assert.Nil(t, ayah)
+
+		finish, err = repo.Rebuild()
+		require.NoError(t, err)
+		require.NoError(t, repo.Index(testAyahs))
+		require.NoError(t, finish(false))
+		count, err = repo.GetDocCount()
+		require.NoError(t, err)
+		assert.Equal(t, uint64(2), count)
+	})
+}
+
+func TestQuranSearchRepository_RebuildOldMapping(t *testing.T) {
+	indexPath := filepath.Join(t.TempDir(), "quran.bleve")
+	// An index from before the stemmed fields, with the default mapping.
+	oldIndex, err := bleve.New(indexPath, bleve.NewIndexMapping())
+	require.NoError(t, err)
+	require.NoError(t, oldIndex.SetInternal(mappingVersionKey, []byte("1")))
+	require.NoError(t, oldIndex.Close())
+
+	repo, err := NewQuranSearchRepository(&config.Config{SearchIndexPath: indexPath})
+	require.NoError(t, err)
+	stemmed := domain.SearchParams{
+		Clauses: []domain.QueryClause{{Text: "keimanan", Occur: domain.OccurMust}},
+		Fields:  []string{domain.FieldTranslation},
+		Page:    1,
+		Limit:   10,
+	}
+
+	require.NoError(t, repo.Index(testAyahs))
+	assert.Empty(t, searchIDs(t, repo, stemmed))
+
+	finish, err := repo.Rebuild()
+	require.NoError(t, err)
+	require.NoError(t, repo.Index(testAyahs))
+	require.NoError(t, finish(true))
+
+	assert.ElementsMatch(t, []string{"2:3", "3:102", "10:9"}, searchIDs(t, repo, stemmed))
+	assert.NoDirExists(t, indexPath+".rebuild")
+	assert.NoDirExists(t, indexPath+".old")
+}
+
+func TestQuranSearchRepository_RebuildFailedSwap(t *testing.T) {
+	indexPath := filepath.Join(t.TempDir(), "quran.bleve")
+	repo, err := NewQuranSearchRepository(&config.Config{SearchIndexPath: indexPath})
+	require.NoError(t, err)
+	require.NoError(t, repo.Index(testAyahs))
+
+	finish, err := repo.Rebuild()
+	require.NoError(t, err)
+	require.NoError(t, repo.Index(testAyahs[:2]))
+	// A rebuilt index that cannot be opened.
+	require.NoError(t, os.WriteFile(filepath.Join(indexPath+".rebuild", "index_meta.json"), []byte("{"), 0o644))
+
+	assert.Error(t, finish(true))
+	count, err := repo.GetDocCount()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(len(testAyahs)), count, "searches go on with the index that was to be replaced")
+	assert.ElementsMatch(t, []string{"2:3", "3:102", "10:9"}, searchIDs(t, repo, domain.SearchParams{Query: "beriman", Page: 1, Limit: 10}))
+	assert.NoDirExists(t, indexPath+".old")
+
+	finish, err = repo.Rebuild()
+	require.NoError(t, err)
+	require.NoError(t, repo.Index(testAyahs[:2]))
+	require.NoError(t, finish(true))
+	count, err = repo.GetDocCount()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), count)
+}
+
+func TestQuranSearchRepository_SearchClauses(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repo domain.QuranSearchRepository) {
+		tests := []struct {
+			name    string
+			clauses []domain.QueryClause
+			fields  []string
+			want    []string
+		}{
+			{"phrase", []domain.QueryClause{
+				{Text: "yang beriman kepada", Phrase: true, Occur: domain.OccurShould},
+			}, nil, []string{"2:3"}},
+			{"required and excluded", []domain.QueryClause{
+				{Text: "beriman", Occur: domain.OccurMust},
+				{Text: "kebajikan", Occur: domain.OccurMustNot},
+			}, nil, []string{"2:3", "3:102"}},
+			{"field scoped", []domain.QueryClause{
+				{Field: domain.FieldTafsir, Text: "perintah", Occur: domain.OccurMust},
+			}, nil, []string{"2:43"}},
+			{"stemmed", []domain.QueryClause{
+				{Text: "keimanan", Occur: domain.OccurMust},
+			}, []string{domain.FieldTranslation}, []string{"2:3", "3:102", "10:9"}},
+			{"synonyms", []domain.QueryClause{
+				{Text: "sholat", Occur: domain.OccurMust, Synonyms: []string{"salat", "shalat"}},
+			}, nil, []string{"2:43"}},
+			{"phrase synonyms", []domain.QueryClause{
+				{Text: "mukmin", Occur: domain.OccurMust, Synonyms: []string{"yang beriman kepada"}},
+			}, nil, []string{"2:3"}},
+			{"section", []domain.QueryClause{
+				{Field: domain.FieldSababNuzul, Text: "khazraj", Occur: domain.OccurMust},
+			}, nil, []string{"3:102"}},
+			{"sections searched by default", []domain.QueryClause{
+				{Text: "pertikaian", Occur: domain.OccurMust},
+			}, nil, []string{"3:102"}},
+			{"juz range", []domain.QueryClause{
+				{Field: domain.FieldJuz, Text: "3-4", Occur: domain.OccurMust},
+			}, nil, []string{"2:255", "3:102"}},
+			{"page and text", []domain.QueryClause{
+				{Field: domain.FieldPage, Text: "209", Occur: domain.OccurMust},
+				{Text: "beriman", Occur: domain.OccurMust},
+			}, nil, []string{"10:9"}},
+			{"surah number", []domain.QueryClause{
+				{Field: domain.FieldSurah, Text: "3", Occur: domain.OccurMust},
+			}, nil, []string{"3:102"}},
+			{"surah name", []domain.QueryClause{
+				{Field: domain.FieldSurah, Text: "ali imran", Occur: domain.OccurMust},
+			}, nil, []string{"3:102"}},
+			{"numeric field without a number", []domain.QueryClause{
+				{Field: domain.FieldJuz, Text: "awal", Occur: domain.OccurMust},
+			}, nil, nil},
+			{"selected fields", []domain.QueryClause{
+				{Text: "bertakwa", Occur: domain.OccurMust},
+			}, []string{domain.FieldTopic}, []string{"2:3", "3:102"}},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				ids := searchIDs(t, repo, domain.SearchParams{Clauses: tt.clauses, Fields: tt.fields, Page: 1, Limit: 10})
+				assert.ElementsMatch(t, tt.want, ids)
+			})
+		}
+	})
+}
+
+func TestQuranSearchRepository_SearchSortAndBoosts(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repo domain.QuranSearchRepository) {
+		ids := searchIDs(t, repo, domain.SearchParams{Query: "beriman", Page: 1, Limit: 10, Sort: []domain.SortField{{Field: domain.SortSurah, Desc: true}}})
+		assert.Equal(t, []string{"10:9", "3:102", "2:3"}, ids)
+
+		ids = searchIDs(t, repo, domain.SearchParams{Query: "beriman", Page: 1, Limit: 10, Sort: []domain.SortField{{Field: domain.SortSurah}}})
+		assert.Equal(t, []string{"2:3", "3:102", "10:9"}, ids)
+
+		// Hits are grouped by surah, ranked by score within each surah.
+		ids = searchIDs(t, repo, domain.SearchParams{
+			Query: "beriman salat",
+			Page:  1,
+			Limit: 10,
+			Sort:  []domain.SortField{{Field: domain.SortSurahNumber, Desc: true}, {Field: domain.SortScore, Desc: true}},
+		})
+		assert.Equal(t, "10:9", ids[0])
+		assert.Equal(t, "3:102", ids[1])
+		assert.ElementsMatch(t, []string{"2:3", "2:43"}, ids[2:])
+
+		ids = searchIDs(t, repo, domain.SearchParams{Query: "orang bertakwa", Fields: []string{domain.FieldTranslation, domain.FieldTopic}, Page: 1, Limit: 10})
+		require.NotEmpty(t, ids)
+		assert.Equal(t, "3:102", ids[0])
+
+		ids = searchIDs(t, repo, domain.SearchParams{
+			Query:  "orang bertakwa",
+			Fields: []string{domain.FieldTranslation, domain.FieldTopic},
+			Boosts: map[string]float64{domain.FieldTopic: 50},
+			Page:   1,
+			Limit:  10,
+		})
+		require.Len(t, ids, 3)
+		assert.ElementsMatch(t, []string{"2:3", "3:102"}, ids[:2])
+	})
+}
+
+func TestQuranSearchRepository_SearchConfig(t *testing.T) {
+	repo, err := NewQuranSearchRepository(&config.Config{
+		SearchIndexPath: filepath.Join(t.TempDir(), "quran.bleve"),
+		Search: config.SearchConfig{
+			Boosts:       map[string]float64{domain.FieldTopic: 50},
+			ScoringModel: domain.ScoringBM25,
+		},
+	})
+	require.NoError(t, err)
+	require.NoError(t, repo.Index(testAyahs))
+
+	ids := searchIDs(t, repo, domain.SearchParams{Query: "orang bertakwa", Fields: []string{domain.FieldTranslation, domain.FieldTopic}, Page: 1, Limit: 10})
+	require.Len(t, ids, 3)
+	assert.ElementsMatch(t, []string{"2:3", "3:102"}, ids[:2])
+
+	// A request boost replaces the configured one.
+	ids = searchIDs(t, repo, domain.SearchParams{
+		Query:  "orang bertakwa",
+		Fields: []string{domain.FieldTranslation, domain.FieldTopic},
+		Boosts: map[string]float64{domain.FieldTopic: 0.1},
+		Page:   1,
+		Limit:  10,
+	})
+	require.NotEmpty(t, ids)
+	assert.Equal(t, "3:102", ids[0])
+
+	bm25, err := repo.Search(domain.SearchParams{Query: "beriman", Page: 1, Limit: 10})
+	require.NoError(t, err)
+	tfidf, err := repo.Search(domain.SearchParams{Query: "beriman", Page: 1, Limit: 10, ScoringModel: domain.ScoringTFIDF})
+	require.NoError(t, err)
+	assert.Equal(t, bm25.Total, tfidf.Total)
+	assert.NotEqual(t, bm25.Hits[0].Score, tfidf.Hits[0].Score)
+
+	for _, search := range []config.SearchConfig{
+		{Boosts: map[string]float64{"arabic": 2}},
+		{Boosts: map[string]float64{domain.FieldTafsir: 0}},
+		{ScoringModel: "dfr"},
+	} {
+		_, err := NewQuranSearchRepository(&config.Config{SearchIndexPath: filepath.Join(t.TempDir(), "quran.bleve"), Search: search})
+		assert.Error(t, err)
+	}
+}
+
+// BenchmarkQuranSearchRepository_ScoringOverride measures searches with the
+// configured scoring model while every tenth search overrides it, which
+// makes that search run alone. Compare with -bench=/none.
+func BenchmarkQuranSearchRepository_ScoringOverride(b *testing.B) {
+	repo, err := NewQuranSearchRepository(&config.Config{
+		SearchIndexPath: filepath.Join(b.TempDir(), "quran.bleve"),
+	})
+	require.NoError(b, err)
+	require.NoError(b, repo.Index(testAyahs))
+
+	for _, bench := range []struct {
+		name  string
+		model string
+	}{
+		{"none", ""},
+		{"bm25", domain.ScoringBM25},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			b.RunParallel(func(pb *testing.PB) {
+				for i := 0; pb.Next(); i++ {
+					params := domain.SearchParams{Query: "orang beriman", Page: 1, Limit: 10}
+					if i%10 == 0 {
+						params.ScoringModel = bench.model
+					}
+					if _, err := repo.Search(params); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		})
+	}
+}
+
+func TestQuranSearchRepository_SearchAfter(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repo domain.QuranSearchRepository) {
+		for _, sort := range [][]domain.SortField{nil, {{Field: domain.SortSurah, Desc: true}}} {
+			all := searchIDs(t, repo, domain.SearchParams{Query: "orang beriman", Sort: sort, Page: 1, Limit: 10})
+			require.Len(t, all, 3)
+
+			var paged []string
+			var after []string
+			for range all {
+				result, err := repo.Search(domain.SearchParams{Query: "orang beriman", Sort: sort, SearchAfter: after, Page: 1, Limit: 1})
+				require.NoError(t, err)
+				require.Len(t, result.Hits, 1)
+				paged = append(paged, hitID(result.Hits[0]))
+				after = result.Hits[0].SortValues
+			}
+			assert.Equal(t, all, paged)
+
+			result, err := repo.Search(domain.SearchParams{Query: "orang beriman", Sort: sort, SearchAfter: after, Page: 1, Limit: 1})
+			require.NoError(t, err)
+			assert.Empty(t, result.Hits)
+		}
+	})
+}
+
+func TestQuranSearchRepository_SearchTranslation(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repo domain.QuranSearchRepository) {
+		result, err := repo.Search(domain.SearchParams{Query: "believed", Translation: "en.sahih", Page: 1, Limit: 10, Highlight: domain.HighlightHTML})
+		require.NoError(t, err)
+		require.Len(t, result.Hits, 1)
+		assert.Equal(t, "3:102", hitID(result.Hits[0]))
+		assert.Equal(t, "O you who have believed, fear Allah", result.Hits[0].Translation)
+		assert.Contains(t, result.Hits[0].Highlights[domain.FieldTranslation][0], "<mark>believed</mark>")
+
+		ids := searchIDs(t, repo, domain.SearchParams{Query: "believed", Page: 1, Limit: 10})
+		assert.Empty(t, ids)
+	})
+}
+
+func TestQuranSearchRepository_SearchTranslationBoosts(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repo domain.QuranSearchRepository) {
+		boosts := map[string]float64{domain.DefaultTranslation: 0.3, "en.sahih": 1.0}
+
+		result, err := repo.Search(domain.SearchParams{Query: "believed", TranslationBoosts: boosts, Page: 1, Limit: 10, Highlight: domain.HighlightHTML})
+		require.NoError(t, err)
+		require.Len(t, result.Hits, 1)
+		assert.Equal(t, "3:102", hitID(result.Hits[0]))
+		assert.Equal(t, "en.sahih", result.Hits[0].MatchedTranslation)
+		assert.Equal(t, "O you who have believed, fear Allah", result.Hits[0].Translation)
+		assert.Contains(t, result.Hits[0].Highlights[domain.FieldTranslation][0], "<mark>believed</mark>")
+		assert.Empty(t, result.Hits[0].Translations)
+
+		result, err = repo.Search(domain.SearchParams{Query: "bertakwalah", TranslationBoosts: boosts, Page: 1, Limit: 10})
+		require.NoError(t, err)
+		require.NotEmpty(t, result.Hits)
+		assert.Equal(t, "3:102", hitID(result.Hits[0]))
+		assert.Equal(t, domain.DefaultTranslation, result.Hits[0].MatchedTranslation)
+		assert.Equal(t, "wahai orang-orang yang beriman, bertakwalah", result.Hits[0].Translation)
+	})
+}
+
+func TestQuranSearchRepository_Related(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repo domain.QuranSearchRepository) {
+		result, err := repo.Related("2:3", 10)
+		require.NoError(t, err)
+		require.NotEmpty(t, result.Hits)
+		assert.Equal(t, "3:102", hitID(result.Hits[0]))
+		assert.Contains(t, result.Hits[0].MatchedTerms, "sifat")
+		for _, hit := range result.Hits {
+			assert.NotEqual(t, "2:3", hitID(hit))
+		}
+
+		result, err = repo.Related("99:1", 10)
+		require.NoError(t, err)
+		assert.Nil(t, result)
+	})
+}
+
+func TestQuranSearchRepository_SearchHighlight(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repo domain.QuranSearchRepository) {
+		result, err := repo.Search(domain.SearchParams{Query: "bertakwalah", Page: 1, Limit: 10, Highlight: domain.HighlightHTML, OmitTafsir: true})
+		require.NoError(t, err)
+		require.NotEmpty(t, result.Hits)
+		require.Equal(t, "3:102", hitID(result.Hits[0]))
+		assert.Contains(t, result.Hits[0].Highlights[domain.FieldTranslation][0], "<mark>bertakwalah</mark>")
+		assert.Empty(t, result.Hits[0].Tafsir)
+
+		result, err = repo.Search(domain.SearchParams{Query: "bertakwalah", Page: 1, Limit: 10, Highlight: domain.HighlightPlain})
+		require.NoError(t, err)
+		require.NotEmpty(t, result.Hits)
+		require.Equal(t, "3:102", hitID(result.Hits[0]))
+		assert.Equal(t, "wahai orang-orang yang beriman, bertakwalah", result.Hits[0].Highlights[domain.FieldTranslation][0])
+	})
+}
+
+func TestQuranSearchRepository_SearchFacets(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repo domain.QuranSearchRepository) {
+		result, err := repo.Search(domain.SearchParams{
+			Query:  "beriman",
+			Page:   1,
+			Limit:  10,
+			Filter: domain.SearchFilter{Location: domain.LocationMadaniyah},
+			Facets: []string{domain.FacetSurah, domain.FacetJuz, domain.FacetTopic},
+		})
+		require.NoError(t, err)
+
+		surahs := result.Facets[domain.FacetSurah]
+		require.Len(t, surahs, 2)
+		assert.ElementsMatch(t, []string{"2", "3"}, []string{surahs[0].Value, surahs[1].Value})
+
+		topics := result.Facets[domain.FacetTopic]
+		require.Len(t, topics, 1)
+		assert.Equal(t, "Sifat orang bertakwa", topics[0].Value)
+		assert.Equal(t, 2, topics[0].Count)
+	})
+}
+
+func TestQuranSearchRepository_Typos(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repo domain.QuranSearchRepository) {
+		fuzziness := 1
+		ids := searchIDs(t, repo, domain.SearchParams{Query: "shalat", Page: 1, Limit: 10, Fuzziness: &fuzziness})
+		assert.Contains(t, ids, "2:43")
+
+		suggestions, err := repo.SpellingSuggestions("sholat zakat", 3)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"salat zakat"}, suggestions)
+
+		suggestions, err = repo.SpellingSuggestions("salat zakat", 3)
+		require.NoError(t, err)
+		assert.Empty(t, suggestions)
+	})
+}
+
+func TestQuranSearchRepository_Complete(t *testing.T) {
+	forEachBackend(t, func(t *testing.T, repo domain.QuranSearchRepository) {
+		completions, err := repo.Complete("Ber", 2)
+		require.NoError(t, err)
+		require.Len(t, completions, 2)
+		assert.Equal(t, "beriman", completions[0].Text)
+		assert.Equal(t, 3, completions[0].Count)
+		assert.Equal(t, domain.CompletionTerm, completions[0].Type)
+	})
+}
@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"math"
+	"sort"
+
+	"github.com/anugrahsputra/go-quran-api/internal/domain"
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/search/query"
+)
+
+// relatedField is a stored field whose terms describe a verse when looking
+// for similar verses.
+type relatedField struct {
+	source   string
+	field    string
+	analyzer string
+	weight   float64
+}
+
+var relatedFields = []relatedField{
+	{source: "Translation", field: "Translation_stem", analyzer: indonesianAnalyzer, weight: 1.0},
+	{source: "Tafsir", field: "Tafsir_stem", analyzer: indonesianAnalyzer, weight: 0.5},
+	{source: "Topic", field: "Topic", analyzer: "standard", weight: 1.5},
+}
+
+const (
+	maxRelatedTerms      = 25
+	minRelatedTermLength = 3
+	// maxRelatedDocShare drops terms found in more than this share of
+	// verses, which say little about a verse.
+	maxRelatedDocShare = 0.5
+)
+
+type relatedTerm struct {
+	field string
+	term  string
+	score float64
+}
+
+// Related runs a more-like-this query: the source verse's Translation,
+// Tafsir and Topic are analyzed like the index does, and the terms with the
+// highest TF-IDF weight are searched, each boosted by its weight. Verses
+// with the same topic get a boost equal to the best term.
+func (r *quranSearchRepository) Related(id string, limit int) (*domain.IndexResults, error) {
+	index, release := r.index.acquire()
+	defer release()
+
+	source, err := storedFields(index, id)
+	if err != nil {
+		return nil, err
+	}
+	if source == nil {
+		return nil, nil
+	}
+
+	docCount, err := index.DocCount()
+	if err != nil {
+		return nil, err
+	}
+
+	terms, err := relatedTerms(index, source, docCount)
+	if err != nil {
+		return nil, err
+	}
+
+	var queries []query.Query
+	for _, term := range terms {
+		termQuery := bleve.NewTermQuery(term.term)
+		termQuery.SetField(term.field)
+		termQuery.SetBoost(term.score)
+		queries = append(queries, termQuery)
+	}
+	if topic, _ := source["Topic"].(string); topic != "" && len(terms) > 0 {
+		topicQuery := bleve.NewTermQuery(topic)
+		topicQuery.SetField("Topic_facet")
+		topicQuery.SetBoost(terms[0].score)
+		queries = append(queries, topicQuery)
+	}
+	if len(queries) == 0 {
+		return &domain.IndexResults{}, nil
+	}
+
+	similar := bleve.NewDisjunctionQuery(queries...)
+	similar.SetMin(1)
+	relatedQuery := bleve.NewBooleanQuery()
+	relatedQuery.AddMust(similar)
+	relatedQuery.AddMustNot(bleve.NewDocIDQuery([]string{id}))
+
+	searchRequest := bleve.NewSearchRequest(relatedQuery)
+	searchRequest.Fields = []string{"SurahNumber", "AyahNumber", "Text", "Latin", "Kitabah", "SurahName", "Translation", "Topic", "Juz", "Page", "Location"}
+	searchRequest.Size = limit
+	searchRequest.IncludeLocations = true
+
+	result, err := index.Search(searchRequest)
+	if err != nil {
+		return nil, err
+	}
+	return indexResults(result, false), nil
+}
+
+// relatedTerms returns the most distinctive terms of a stored document,
+// highest weight first. A term's weight is its field weight times
+// (1 + ln tf) times its inverse document frequency.
+func relatedTerms(index bleve.Index, source map[string]any, docCount uint64) ([]relatedTerm, error) {
+	var terms []relatedTerm
+	for _, field := range relatedFields {
+		text, _ := source[field.source].(string)
+		if text == "" {
+			continue
+		}
+
+		analyzer := index.Mapping().AnalyzerNamed(field.analyzer)
+		if analyzer == nil {
+			continue
+		}
+
+		var tokens []string
+		for _, token := range analyzer.Analyze([]byte(text)) {
+			tokens = append(tokens, string(token.Term))
+		}
+		fieldTerms, err := weighRelatedTerms(field, tokens, docCount, func(term string) (uint64, error) {
+			return docFrequency(index, field.field, term)
+		})
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, fieldTerms...)
+	}
+
+	return topRelatedTerms(terms), nil
+}
+
+// weighRelatedTerms weighs the analyzed tokens of one field of the source
+// verse, given the number of documents holding each term.
+func weighRelatedTerms(field relatedField, tokens []string, docCount uint64, docFrequency func(string) (uint64, error)) ([]relatedTerm, error) {
+	frequencies := make(map[string]int)
+	for _, token := range tokens {
+		if len(token) >= minRelatedTermLength {
+			frequencies[token]++
+		}
+	}
+
+	var terms []relatedTerm
+	for term, tf := range frequencies {
+		df, err := docFrequency(term)
+		if err != nil {
+			return nil, err
+		}
+		// Terms only in the source verse cannot match another one.
+		if df < 2 || float64(df) > maxRelatedDocShare*float64(docCount) {
+			continue
+		}
+		idf := 1 + math.Log(float64(docCount)/float64(df+1))
+		terms = append(terms, relatedTerm{
+			field: field.field,
+			term:  term,
+			score: field.weight * (1 + math.Log(float64(tf))) * idf,
+		})
+	}
+	return terms, nil
+}
+
+// topRelatedTerms keeps the maxRelatedTerms highest weighted terms, highest
+// first.
+func topRelatedTerms(terms []relatedTerm) []relatedTerm {
+	sort.Slice(terms, func(i, j int) bool {
+		if terms[i].score != terms[j].score {
+			return terms[i].score > terms[j].score
+		}
+		return terms[i].term < terms[j].term
+	})
+	if len(terms) > maxRelatedTerms {
+		terms = terms[:maxRelatedTerms]
+	}
+	return terms
+}
+
+func docFrequency(index bleve.Index, field, term string) (uint64, error) {
+	dict, err := index.FieldDictRange(field, []byte(term), []byte(term))
+	if err != nil {
+		return 0, err
+	}
+	defer dict.Close()
+
+	entry, err := dict.Next()
+	if err != nil || entry == nil || entry.Term != term {
+		return 0, err
+	}
+	return entry.Count, nil
+}
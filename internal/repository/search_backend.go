@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/anugrahsputra/go-quran-api/config"
+	"github.com/anugrahsputra/go-quran-api/internal/domain"
+)
+
+// NewSearchRepositories opens the search backend selected by
+// cfg.Search.Backend. The memory backend has no tafsir paragraph index, so
+// it returns no TafsirSearchRepository.
+func NewSearchRepositories(cfg *config.Config) (domain.QuranSearchRepository, domain.TafsirSearchRepository, error) {
+	switch cfg.Search.Backend {
+	case "", config.SearchBackendBleve:
+		searchRepo, err := NewQuranSearchRepository(cfg)
+		if err != nil {
+			return nil, nil, err
+		}
+		tafsirRepo, err := NewTafsirSearchRepository(cfg)
+		if err != nil {
+			// Release the ayah index, which holds its file lock while open.
+			return nil, nil, errors.Join(fmt.Errorf("failed to create tafsir search repository: %w", err), searchRepo.Close())
+		}
+		return searchRepo, tafsirRepo, nil
+	case config.SearchBackendMemory:
+		searchRepo, err := NewMemorySearchRepository(cfg)
+		if err != nil {
+			return nil, nil, err
+		}
+		return searchRepo, nil, nil
+	default:
+		return nil, nil, fmt.Errorf("invalid SEARCH_BACKEND %q: must be %s or %s", cfg.Search.Backend, config.SearchBackendBleve, config.SearchBackendMemory)
+	}
+}
@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/anugrahsputra/go-quran-api/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSearchRepositories_TafsirFailure(t *testing.T) {
+	dir := t.TempDir()
+	blocked := filepath.Join(dir, "blocked")
+	require.NoError(t, os.WriteFile(blocked, nil, 0o644))
+	cfg := &config.Config{SearchIndexPath: filepath.Join(dir, "quran.bleve")}
+	// A path under a file, which no index can be created at.
+	cfg.Search.TafsirIndexPath = filepath.Join(blocked, "quran_tafsir.bleve")
+
+	_, _, err := NewSearchRepositories(cfg)
+	require.Error(t, err)
+
+	// The ayah index was released, so it opens again.
+	repo, err := NewQuranSearchRepository(cfg)
+	require.NoError(t, err)
+	assert.True(t, repo.IsHealthy())
+	assert.NoError(t, repo.Close())
+}
@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/anugrahsputra/go-quran-api/internal/domain"
+	"github.com/blevesearch/bleve/v2/numeric"
+	"github.com/blevesearch/bleve/v2/search"
+)
+
+// termWeightPattern matches the explanation node bleve writes for a matched
+// term, e.g. "weight(Translation:sabar^5.000000 in 2:45), product of:", or
+// "fieldWeight(Translation:sabar in 2:45), as per tf-idf model, product
+// of:" when the query weight is 1 and no weight node wraps it. Document
+// IDs are internal binary IDs and may hold any byte.
+var termWeightPattern = regexp.MustCompile(`(?s)^(?:weight\(([^:^]+):(.*)\^([0-9.eE+-]+)|fieldWeight\(([^:^]+):(.*)) in .*\), (?:as per .* model, )?product of:$`)
+
+// constantWeightPattern matches the node bleve writes for a clause scored
+// with a constant, such as a document ID query, e.g. "weight(^2.000000),
+// product of:", or the bare "ConstantScore()" when its query weight is 1.
+var constantWeightPattern = regexp.MustCompile(`^(?:weight\(\^([0-9.eE+-]+)\), product of:|ConstantScore\(\))$`)
+
+// constantScoreField is the field listed for constant-scored clauses.
+const constantScoreField = "ConstantScore"
+
+// numericFields are the index fields whose terms are prefix-coded numbers,
+// as range queries such as juz:1..4 and filters match them.
+var numericFields = map[string]bool{"SurahNumber": true, "AyahNumber": true, "Juz": true, "Page": true, "Paragraph": true}
+
+// coordPattern matches the factor a disjunction applies when only some of
+// its clauses match, e.g. "coord(2/5)".
+var coordPattern = regexp.MustCompile(`^coord\(\d+/\d+\)$`)
+
+// explainScore summarizes a bleve explanation per field and term.
+func explainScore(expl *search.Explanation) *domain.ScoreExplanation {
+	if expl == nil {
+		return nil
+	}
+
+	fields := make(map[string]*domain.FieldContribution)
+	collectContributions(expl, 1, fields)
+	return newScoreExplanation(expl.Value, fields, expl)
+}
+
+// newScoreExplanation lists the field contributions to a score, highest
+// first, each with its terms highest first.
+func newScoreExplanation(score float64, fields map[string]*domain.FieldContribution, tree any) *domain.ScoreExplanation {
+	explanation := &domain.ScoreExplanation{
+		Score:  score,
+		Fields: make([]domain.FieldContribution, 0, len(fields)),
+		Tree:   tree,
+	}
+	for _, field := range fields {
+		sort.Slice(field.Terms, func(i, j int) bool { return field.Terms[i].Score > field.Terms[j].Score })
+		explanation.Fields = append(explanation.Fields, *field)
+	}
+	sort.Slice(explanation.Fields, func(i, j int) bool {
+		if explanation.Fields[i].Score != explanation.Fields[j].Score {
+			return explanation.Fields[i].Score > explanation.Fields[j].Score
+		}
+		return explanation.Fields[i].Field < explanation.Fields[j].Field
+	})
+	return explanation
+}
+
+// collectContributions walks the explanation tree, scaling term weights by
+// the coordination factors of the disjunctions above them.
+func collectContributions(expl *search.Explanation, scale float64, fields map[string]*domain.FieldContribution) {
+	if m := termWeightPattern.FindStringSubmatch(expl.Message); m != nil {
+		// Without a weight node the query weight, and so the boost, is 1.
+		name, term, boost := m[4], m[5], 1.0
+		if m[1] != "" {
+			name, term = m[1], m[2]
+			boost, _ = strconv.ParseFloat(m[3], 64)
+		}
+		if numericFields[name] {
+			term = numericTerm(term)
+		}
+		addContribution(fields, name, domain.TermContribution{Term: term, Boost: boost, Score: expl.Value * scale})
+		return
+	}
+	if m := constantWeightPattern.FindStringSubmatch(expl.Message); m != nil {
+		boost := 1.0
+		if m[1] != "" {
+			boost, _ = strconv.ParseFloat(m[1], 64)
+		}
+		addContribution(fields, constantScoreField, domain.TermContribution{Boost: boost, Score: expl.Value * scale})
+		return
+	}
+
+	if strings.HasPrefix(expl.Message, "product of") {
+		for _, child := range expl.Children {
+			if coordPattern.MatchString(child.Message) {
+				scale *= child.Value
+			}
+		}
+	}
+	for _, child := range expl.Children {
+		if !coordPattern.MatchString(child.Message) {
+			collectContributions(child, scale, fields)
+		}
+	}
+}
+
+func addContribution(fields map[string]*domain.FieldContribution, name string, term domain.TermContribution) {
+	field := fields[name]
+	if field == nil {
+		field = &domain.FieldContribution{Field: name}
+		fields[name] = field
+	}
+	field.Score += term.Score
+	field.Terms = append(field.Terms, term)
+}
+
+// numericTerm decodes a prefix-coded number term. Terms covering a range of
+// numbers, which range queries match besides single numbers, are written as
+// the lowest number followed by "..".
+func numericTerm(term string) string {
+	coded := numeric.PrefixCoded(term)
+	value, err := coded.Int64()
+	if err != nil {
+		return term
+	}
+	formatted := strconv.FormatFloat(numeric.Int64ToFloat64(value), 'f', -1, 64)
+	if shift, _ := coded.Shift(); shift > 0 {
+		return formatted + ".."
+	}
+	return formatted
+}
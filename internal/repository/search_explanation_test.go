@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/anugrahsputra/go-quran-api/config"
+	"github.com/anugrahsputra/go-quran-api/internal/domain"
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/search/query"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExplainScore(t *testing.T) {
+	assert.Nil(t, explainScore(nil))
+
+	repo, err := NewQuranSearchRepository(&config.Config{
+		SearchIndexPath: filepath.Join(t.TempDir(), "quran.bleve"),
+	})
+	require.NoError(t, err)
+	require.NoError(t, repo.Index(testAyahs))
+	index, release := repo.(*quranSearchRepository).index.acquire()
+	defer release()
+
+	term := func(field, text string, boost float64) query.Query {
+		termQuery := bleve.NewTermQuery(text)
+		termQuery.SetField(field)
+		termQuery.SetBoost(boost)
+		return termQuery
+	}
+	juz := func(value float64) query.Query {
+		inclusive := true
+		rangeQuery := bleve.NewNumericRangeInclusiveQuery(&value, &value, &inclusive, &inclusive)
+		rangeQuery.SetField("Juz")
+		return rangeQuery
+	}
+	explain := func(q query.Query) (float64, *domain.ScoreExplanation) {
+		t.Helper()
+		request := bleve.NewSearchRequest(q)
+		request.Explain = true
+		result, err := index.Search(request)
+		require.NoError(t, err)
+		require.Len(t, result.Hits, 1)
+		return result.Hits[0].Score, explainScore(result.Hits[0].Expl)
+	}
+	fieldScores := func(explanation *domain.ScoreExplanation) (total float64) {
+		for _, field := range explanation.Fields {
+			total += field.Score
+		}
+		return total
+	}
+
+	t.Run("single term", func(t *testing.T) {
+		score, explanation := explain(term("Translation", "zakat", 5))
+		require.Len(t, explanation.Fields, 1)
+		assert.Equal(t, "Translation", explanation.Fields[0].Field)
+		require.Len(t, explanation.Fields[0].Terms, 1)
+		assert.Equal(t, domain.TermContribution{Term: "zakat", Boost: 1, Score: score}, explanation.Fields[0].Terms[0])
+		assert.Equal(t, score, explanation.Score)
+	})
+
+	t.Run("boosted", func(t *testing.T) {
+		score, explanation := explain(bleve.NewDisjunctionQuery(
+			term("Translation", "zakat", 5),
+			term("Tafsir", "zakat", 3),
+			term("Topic", "zakat", 2),
+		))
+		require.Len(t, explanation.Fields, 2)
+		assert.Equal(t, "Translation", explanation.Fields[0].Field)
+		assert.Equal(t, 5.0, explanation.Fields[0].Terms[0].Boost)
+		assert.Equal(t, "Tafsir", explanation.Fields[1].Field)
+		assert.Equal(t, 3.0, explanation.Fields[1].Terms[0].Boost)
+		assert.InDelta(t, score, fieldScores(explanation), 1e-9)
+	})
+
+	t.Run("numeric filter", func(t *testing.T) {
+		score, explanation := explain(bleve.NewConjunctionQuery(
+			term("Translation", "beriman", 5),
+			juz(11),
+			bleve.NewDocIDQuery([]string{"10:9"}),
+		))
+		fields := make(map[string]domain.FieldContribution)
+		for _, field := range explanation.Fields {
+			fields[field.Field] = field
+		}
+		assert.Contains(t, fields, "Translation")
+		require.Contains(t, fields, "Juz")
+		assert.Equal(t, "11", fields["Juz"].Terms[0].Term)
+		assert.Contains(t, fields, constantScoreField)
+		assert.InDelta(t, score, fieldScores(explanation), 1e-9)
+	})
+}
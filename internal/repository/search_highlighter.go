@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"github.com/blevesearch/bleve/v2/registry"
+	"github.com/blevesearch/bleve/v2/search/highlight"
+	"github.com/blevesearch/bleve/v2/search/highlight/format/plain"
+	"github.com/blevesearch/bleve/v2/search/highlight/fragmenter/simple"
+	simpleHighlighter "github.com/blevesearch/bleve/v2/search/highlight/highlighter/simple"
+)
+
+const (
+	htmlHighlighter      = "html"
+	plainTextHighlighter = "plain_text"
+	snippetSize          = 200
+)
+
+// plainTextHighlighterConstructor builds snippets around the matched terms
+// without any markup, for clients that render plain text.
+func plainTextHighlighterConstructor(config map[string]interface{}, cache *registry.Cache) (highlight.Highlighter, error) {
+	return simpleHighlighter.NewHighlighter(
+		simple.NewFragmenter(snippetSize),
+		plain.NewFragmentFormatter("", ""),
+		simpleHighlighter.DefaultSeparator,
+	), nil
+}
+
+func init() {
+	if err := registry.RegisterHighlighter(plainTextHighlighter, plainTextHighlighterConstructor); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/anugrahsputra/go-quran-api/internal/domain"
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/search"
+)
+
+// highlightFields maps the indexed fields that produce snippets to the keys
+// used in the API response.
+var highlightFields = map[string]string{
+	"Translation": domain.FieldTranslation,
+	"Latin":       domain.FieldLatin,
+	"Tafsir":      domain.FieldTafsir,
+	"Topic":       domain.FieldTopic,
+	"Wajiz":       domain.FieldWajiz,
+	"SababNuzul":  domain.FieldSababNuzul,
+	"Kosakata":    domain.FieldKosakata,
+	"Conclusion":  domain.FieldConclusion,
+	"Kitabah":     domain.FieldKitabah,
+	"SurahName":   domain.FieldSurah,
+}
+
+// indexResults converts a bleve result to domain hits, skipping documents
+// without a surah and ayah number.
+func indexResults(result *bleve.SearchResult, explain bool) *domain.IndexResults {
+	results := &domain.IndexResults{
+		Total:  int(result.Total),
+		Facets: convertFacets(result.Facets),
+	}
+	if explain && result.Request != nil {
+		results.Query = result.Request.Query
+	}
+
+	for _, hit := range result.Hits {
+		fields := hit.Fields
+		if len(fields) == 0 {
+			log.Printf("Warning: No fields found in hit - ID: %s, Score: %f", hit.ID, hit.Score)
+
+			parts := splitDocumentID(hit.ID)
+			if len(parts) == 2 {
+				surahNumber, _ := strconv.Atoi(parts[0])
+				ayahNumber, _ := strconv.Atoi(parts[1])
+				if surahNumber > 0 && ayahNumber > 0 {
+					results.Hits = append(results.Hits, domain.IndexHit{
+						SearchedAyah: domain.SearchedAyah{
+							SurahNumber: surahNumber,
+							AyahNumber:  ayahNumber,
+						},
+						Score:      hit.Score,
+						SortValues: hit.DecodedSort,
+					})
+					log.Printf("Warning: Created minimal ayah from ID for %s", hit.ID)
+				}
+			}
+			continue
+		}
+
+		ayah := fieldsToAyah(fields)
+		if ayah.SurahNumber > 0 && ayah.AyahNumber > 0 {
+			results.Hits = append(results.Hits, domain.IndexHit{
+				SearchedAyah:       ayah,
+				Score:              hit.Score,
+				MatchedTranslation: stringField(fields, "MatchedTranslation"),
+				Highlights:         fragmentsToHighlights(hit.Fragments),
+				Explanation:        explainScore(hit.Expl),
+				SortValues:         hit.DecodedSort,
+				MatchedTerms:       termCounts(hit.Locations),
+			})
+		} else {
+			log.Printf("Warning: Skipping hit with incomplete data - ID: %s, SurahNumber: %v, AyahNumber: %v, Fields keys: %v",
+				hit.ID, fields["SurahNumber"], fields["AyahNumber"], getFieldKeys(fields))
+		}
+	}
+	return results
+}
+
+func fieldsToAyah(fields map[string]any) domain.SearchedAyah {
+	ayah := domain.SearchedAyah{
+		SurahNumber: intField(fields, "SurahNumber"),
+		AyahNumber:  intField(fields, "AyahNumber"),
+		Text:        stringField(fields, "Text"),
+		Latin:       stringField(fields, "Latin"),
+		Translation: stringField(fields, "Translation"),
+		Tafsir:      stringField(fields, "Tafsir"),
+		Wajiz:       stringField(fields, "Wajiz"),
+		SababNuzul:  stringField(fields, "SababNuzul"),
+		Kosakata:    stringField(fields, "Kosakata"),
+		Conclusion:  stringField(fields, "Conclusion"),
+		Kitabah:     stringField(fields, "Kitabah"),
+		SurahName:   stringField(fields, "SurahName"),
+		Topic:       stringField(fields, "Topic"),
+		Juz:         intField(fields, "Juz"),
+		Page:        intField(fields, "Page"),
+		Location:    stringField(fields, "Location"),
+	}
+
+	if l, ok := fields["Latin"]; ok {
+		if _, ok := l.(string); !ok {
+			log.Printf("Warning: Latin field exists but is not a string, type: %T, value: %v", l, l)
+		}
+	}
+
+	return ayah
+}
+
+func convertFacets(facets search.FacetResults) map[string][]domain.FacetCount {
+	if len(facets) == 0 {
+		return nil
+	}
+
+	converted := make(map[string][]domain.FacetCount, len(facets))
+	for name, facet := range facets {
+		counts := []domain.FacetCount{}
+		for _, numericRange := range facet.NumericRanges {
+			counts = append(counts, domain.FacetCount{Value: numericRange.Name, Count: numericRange.Count})
+		}
+		if facet.Terms != nil {
+			for _, term := range facet.Terms.Terms() {
+				counts = append(counts, domain.FacetCount{Value: term.Term, Count: term.Count})
+			}
+		}
+		converted[name] = counts
+	}
+	return converted
+}
+
+func fragmentsToHighlights(fragments map[string][]string) map[string][]string {
+	if len(fragments) == 0 {
+		return nil
+	}
+
+	highlights := make(map[string][]string, len(fragments))
+	for field, snippets := range fragments {
+		if key, ok := highlightFields[field]; ok && len(snippets) > 0 {
+			highlights[key] = snippets
+		}
+	}
+	return highlights
+}
+
+// termCounts counts the occurrences of each matched term across fields.
+func termCounts(locations search.FieldTermLocationMap) map[string]int {
+	if len(locations) == 0 {
+		return nil
+	}
+
+	counts := make(map[string]int)
+	for _, terms := range locations {
+		for term, termLocations := range terms {
+			counts[term] += len(termLocations)
+		}
+	}
+	return counts
+}
+
+func intField(fields map[string]any, name string) int {
+	switch v := fields[name].(type) {
+	case float64:
+		return int(v)
+	case int:
+		return v
+	case int64:
+		return int(v)
+	case int32:
+		return int(v)
+	}
+	return 0
+}
+
+func stringField(fields map[string]any, name string) string {
+	if v, ok := fields[name].(string); ok {
+		return v
+	}
+	return ""
+}
+
+func getFieldKeys(fields map[string]any) []string {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+func splitDocumentID(id string) []string {
+	return strings.Split(id, ":")
+}
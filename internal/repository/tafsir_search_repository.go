@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/anugrahsputra/go-quran-api/config"
+	"github.com/anugrahsputra/go-quran-api/internal/domain"
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/search/query"
+)
+
+// tafsirIndexMappingVersion versions createTafsirIndex like
+// indexMappingVersion does for the ayah index.
+const tafsirIndexMappingVersion = "1"
+
+type tafsirSearchRepository struct {
+	index *liveIndex
+	path  string
+}
+
+// NewTafsirSearchRepository opens the tafsir paragraph index, which is kept
+// apart from the ayah index so that paragraphs never show up as ayahs.
+func NewTafsirSearchRepository(cfg *config.Config) (domain.TafsirSearchRepository, error) {
+	scoringModel, err := scoringModelOrDefault(cfg.Search.ScoringModel)
+	if err != nil {
+		return nil, err
+	}
+
+	opened := func(index bleve.Index) {
+		setScoringModel(index, scoringModel)
+	}
+
+	index, err := openIndex(cfg.Search.TafsirIndexPath, tafsirIndexMappingVersion, createTafsirIndex)
+	if err != nil {
+		return nil, err
+	}
+	opened(index)
+
+	return &tafsirSearchRepository{
+		index: newLiveIndex(cfg.Search.TafsirIndexPath, index, createTafsirIndex, opened),
+		path:  cfg.Search.TafsirIndexPath,
+	}, nil
+}
+
+func createTafsirIndex(indexPath string) (bleve.Index, error) {
+	indexMapping := bleve.NewIndexMapping()
+	if err := addTextAnalyzers(indexMapping); err != nil {
+		return nil, err
+	}
+
+	paragraphMapping := bleve.NewDocumentMapping()
+
+	for _, field := range []string{"SurahNumber", "AyahNumber", "Paragraph", "Juz"} {
+		numericMapping := bleve.NewNumericFieldMapping()
+		numericMapping.Store = true
+		paragraphMapping.AddFieldMappingsAt(field, numericMapping)
+	}
+
+	locationMapping := bleve.NewTextFieldMapping()
+	locationMapping.Store = true
+	locationMapping.Analyzer = "keyword_lower"
+	paragraphMapping.AddFieldMappingsAt("Location", locationMapping)
+
+	tafsirStd := bleve.NewTextFieldMapping()
+	tafsirStd.Store = true
+	tafsirStd.Analyzer = "standard"
+	paragraphMapping.AddFieldMappingsAt("Tafsir", tafsirStd)
+
+	tafsirStem := bleve.NewTextFieldMapping()
+	tafsirStem.Store = false
+	tafsirStem.Analyzer = indonesianAnalyzer
+	paragraphMapping.AddFieldMappingsAt("Tafsir_stem", tafsirStem)
+
+	// The verse is only returned with the paragraph, never searched.
+	for _, field := range []string{"Text", "Translation"} {
+		verseMapping := bleve.NewTextFieldMapping()
+		verseMapping.Store = true
+		verseMapping.Index = false
+		verseMapping.IncludeInAll = false
+		verseMapping.IncludeTermVectors = false
+		paragraphMapping.AddFieldMappingsAt(field, verseMapping)
+	}
+
+	indexMapping.DefaultAnalyzer = "standard"
+	indexMapping.DefaultMapping = paragraphMapping
+
+	return newIndex(indexPath, indexMapping, tafsirIndexMappingVersion)
+}
+
+func (r *tafsirSearchRepository) Index(paragraphs []domain.TafsirParagraph) error {
+	index, release := r.index.acquireTarget()
+	defer release()
+
+	batch := index.NewBatch()
+
+	for _, paragraph := range paragraphs {
+		ref := domain.VerseRef{Surah: paragraph.SurahNumber, Ayah: paragraph.AyahNumber}
+		id := fmt.Sprintf("%s:%d", ref, paragraph.Paragraph)
+
+		doc := map[string]any{
+			"SurahNumber": paragraph.SurahNumber,
+			"AyahNumber":  paragraph.AyahNumber,
+			"Paragraph":   paragraph.Paragraph,
+			"Tafsir":      paragraph.Tafsir,
+			"Tafsir_stem": paragraph.Tafsir,
+			"Text":        paragraph.Text,
+			"Translation": paragraph.Translation,
+			"Juz":         paragraph.Juz,
+			"Location":    paragraph.Location,
+		}
+
+		if err := batch.Index(id, doc); err != nil {
+			return err
+		}
+	}
+
+	log.Printf("Indexed %d tafsir paragraphs to %s", len(paragraphs), r.path)
+	return index.Batch(batch)
+}
+
+// Rebuild directs Index to a new index, replacing the searched one when
+// the returned function is called with true. Paragraph IDs are numbered per
+// verse, so only a rebuild drops the paragraphs of a tafsir that got
+// shorter.
+func (r *tafsirSearchRepository) Rebuild() (func(keep bool) error, error) {
+	return r.index.rebuild()
+}
+
+func (r *tafsirSearchRepository) Search(params domain.SearchParams) (domain.TafsirResults, error) {
+	page, limit := params.Page, params.Limit
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 10
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	params.Fields = []string{domain.FieldTafsir}
+	searchQuery := buildTextQuery(params, 0)
+	if filters := buildFilterQueries(params.Filter); len(filters) > 0 {
+		searchQuery = bleve.NewConjunctionQuery(append([]query.Query{searchQuery}, filters...)...)
+	}
+
+	searchRequest := bleve.NewSearchRequest(searchQuery)
+	searchRequest.Fields = []string{"SurahNumber", "AyahNumber", "Paragraph", "Tafsir", "Text", "Translation", "Juz", "Location"}
+	searchRequest.Size = limit
+	searchRequest.From = (page - 1) * limit
+
+	switch params.Highlight {
+	case domain.HighlightHTML:
+		searchRequest.Highlight = bleve.NewHighlightWithStyle(htmlHighlighter)
+	case domain.HighlightPlain:
+		searchRequest.Highlight = bleve.NewHighlightWithStyle(plainTextHighlighter)
+	}
+	if searchRequest.Highlight != nil {
+		searchRequest.Highlight.AddField("Tafsir")
+	}
+
+	if len(params.Sort) > 0 {
+		searchRequest.SortByCustom(append(buildSortOrder(params.Sort), numericSort("Paragraph", false)))
+	}
+
+	index, release := r.index.acquire()
+	result, err := index.Search(searchRequest)
+	release()
+	if err != nil {
+		return domain.TafsirResults{}, err
+	}
+
+	hits := make([]domain.TafsirHit, 0, len(result.Hits))
+	for _, hit := range result.Hits {
+		fields := hit.Fields
+		hits = append(hits, domain.TafsirHit{
+			TafsirParagraph: domain.TafsirParagraph{
+				SurahNumber: intField(fields, "SurahNumber"),
+				AyahNumber:  intField(fields, "AyahNumber"),
+				Paragraph:   intField(fields, "Paragraph"),
+				Tafsir:      stringField(fields, "Tafsir"),
+				Text:        stringField(fields, "Text"),
+				Translation: stringField(fields, "Translation"),
+				Juz:         intField(fields, "Juz"),
+				Location:    stringField(fields, "Location"),
+			},
+			Highlights: hit.Fragments["Tafsir"],
+		})
+	}
+
+	return domain.TafsirResults{Hits: hits, Total: int(result.Total)}, nil
+}
+
+func (r *tafsirSearchRepository) GetDocCount() (uint64, error) {
+	index, release := r.index.acquire()
+	defer release()
+	return index.DocCount()
+}
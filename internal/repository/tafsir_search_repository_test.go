@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/anugrahsputra/go-quran-api/config"
+	"github.com/anugrahsputra/go-quran-api/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testParagraphs = []domain.TafsirParagraph{
+	{SurahNumber: 3, AyahNumber: 121, Paragraph: 1, Tafsir: "Ayat ini menceritakan persiapan kaum Muslimin sebelum Perang Uhud.", Translation: "Dan (ingatlah), ketika engkau (Muhammad) berangkat pada pagi hari", Juz: 4, Location: domain.LocationMadaniyah},
+	{SurahNumber: 3, AyahNumber: 121, Paragraph: 2, Tafsir: "Nabi menempatkan pasukan pemanah di atas bukit.", Juz: 4, Location: domain.LocationMadaniyah},
+	{SurahNumber: 8, AyahNumber: 17, Paragraph: 1, Tafsir: "Kemenangan dalam Perang Badar adalah pertolongan Allah.", Juz: 9, Location: domain.LocationMadaniyah},
+}
+
+func TestTafsirSearchRepository_Search(t *testing.T) {
+	repo, err := NewTafsirSearchRepository(&config.Config{
+		Search: config.SearchConfig{TafsirIndexPath: filepath.Join(t.TempDir(), "tafsir.bleve")},
+	})
+	require.NoError(t, err)
+	require.NoError(t, repo.Index(testParagraphs))
+
+	count, err := repo.GetDocCount()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), count)
+
+	result, err := repo.Search(domain.SearchParams{
+		Clauses:   []domain.QueryClause{{Text: "perang uhud", Phrase: true, Occur: domain.OccurShould}},
+		Highlight: domain.HighlightHTML,
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Hits, 1)
+	assert.Equal(t, testParagraphs[0].Paragraph, result.Hits[0].Paragraph)
+	assert.Equal(t, domain.VerseRef{Surah: 3, Ayah: 121}, domain.VerseRef{Surah: result.Hits[0].SurahNumber, Ayah: result.Hits[0].AyahNumber})
+	assert.Equal(t, "Dan (ingatlah), ketika engkau (Muhammad) berangkat pada pagi hari", result.Hits[0].Translation)
+	assert.Contains(t, result.Hits[0].Highlights[0], "<mark>Uhud</mark>")
+
+	result, err = repo.Search(domain.SearchParams{
+		Clauses: []domain.QueryClause{{Text: "perang", Occur: domain.OccurShould}},
+		Filter:  domain.SearchFilter{Surah: 8},
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Hits, 1)
+	assert.Equal(t, 8, result.Hits[0].SurahNumber)
+
+	// Verse text is stored with the paragraph but not searched.
+	result, err = repo.Search(domain.SearchParams{
+		Clauses: []domain.QueryClause{{Text: "berangkat", Occur: domain.OccurShould}},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, result.Hits)
+}
+
+func TestTafsirSearchRepository_Rebuild(t *testing.T) {
+	repo, err := NewTafsirSearchRepository(&config.Config{
+		Search: config.SearchConfig{TafsirIndexPath: filepath.Join(t.TempDir(), "tafsir.bleve")},
+	})
+	require.NoError(t, err)
+	require.NoError(t, repo.Index(testParagraphs))
+
+	// The tafsir of 3:121 lost its second paragraph.
+	finish, err := repo.Rebuild()
+	require.NoError(t, err)
+	require.NoError(t, repo.Index([]domain.TafsirParagraph{testParagraphs[0], testParagraphs[2]}))
+	require.NoError(t, finish(true))
+
+	count, err := repo.GetDocCount()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), count)
+
+	result, err := repo.Search(domain.SearchParams{
+		Clauses: []domain.QueryClause{{Text: "pemanah", Occur: domain.OccurShould}},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, result.Hits)
+}
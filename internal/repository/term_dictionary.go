@@ -0,0 +1,272 @@
+package repository
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/anugrahsputra/go-quran-api/internal/domain"
+	"github.com/blevesearch/bleve/v2"
+)
+
+type termEntry struct {
+	Term  string
+	Count int
+}
+
+// termDictionary is an in-memory copy of the index term dictionaries for a
+// set of fields, with counts summed across fields. It is built lazily from a
+// bleve index, or up front by an in-memory one, and dropped whenever the
+// index changes.
+type termDictionary struct {
+	fields []string
+
+	mu     sync.RWMutex
+	built  bool
+	counts map[string]int
+	terms  []termEntry // sorted by term
+}
+
+func newTermDictionary(fields ...string) *termDictionary {
+	return &termDictionary{fields: fields}
+}
+
+func (d *termDictionary) invalidate() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.built = false
+	d.counts = nil
+	d.terms = nil
+}
+
+func (d *termDictionary) load(index bleve.Index) error {
+	d.mu.RLock()
+	built := d.built
+	d.mu.RUnlock()
+	if built {
+		return nil
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.built {
+		return nil
+	}
+
+	counts := make(map[string]int)
+	for _, field := range d.fields {
+		dict, err := index.FieldDict(field)
+		if err != nil {
+			return err
+		}
+		for {
+			entry, err := dict.Next()
+			if err != nil {
+				_ = dict.Close()
+				return err
+			}
+			if entry == nil {
+				break
+			}
+			counts[entry.Term] += int(entry.Count)
+		}
+		if err := dict.Close(); err != nil {
+			return err
+		}
+	}
+
+	d.set(counts)
+	return nil
+}
+
+// build fills the dictionary from the document frequencies of each field.
+func (d *termDictionary) build(docFreqs map[string]map[string]int) {
+	counts := make(map[string]int)
+	for _, field := range d.fields {
+		for term, count := range docFreqs[field] {
+			counts[term] += count
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.set(counts)
+}
+
+// set replaces the terms. The caller holds the write lock.
+func (d *termDictionary) set(counts map[string]int) {
+	terms := make([]termEntry, 0, len(counts))
+	for term, count := range counts {
+		terms = append(terms, termEntry{Term: term, Count: count})
+	}
+	sort.Slice(terms, func(i, j int) bool { return terms[i].Term < terms[j].Term })
+
+	d.counts = counts
+	d.terms = terms
+	d.built = true
+}
+
+func (d *termDictionary) count(term string) int {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.counts[term]
+}
+
+// closest returns up to limit dictionary terms within maxDistance edits of
+// term, nearest first and most frequent first among equals.
+func (d *termDictionary) closest(term string, maxDistance, limit int) []string {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	type candidate struct {
+		termEntry
+		distance int
+	}
+
+	length := len([]rune(term))
+	var candidates []candidate
+	for _, entry := range d.terms {
+		if entry.Term == term || abs(len([]rune(entry.Term))-length) > maxDistance {
+			continue
+		}
+		if distance := levenshtein(term, entry.Term, maxDistance); distance <= maxDistance {
+			candidates = append(candidates, candidate{termEntry: entry, distance: distance})
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].distance != candidates[j].distance {
+			return candidates[i].distance < candidates[j].distance
+		}
+		return candidates[i].Count > candidates[j].Count
+	})
+
+	result := make([]string, 0, limit)
+	for i := 0; i < len(candidates) && i < limit; i++ {
+		result = append(result, candidates[i].Term)
+	}
+	return result
+}
+
+// withPrefix returns up to limit dictionary terms starting with prefix,
+// most frequent first.
+func (d *termDictionary) withPrefix(prefix string, limit int) []termEntry {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	start := sort.Search(len(d.terms), func(i int) bool { return d.terms[i].Term >= prefix })
+
+	var matches []termEntry
+	for i := start; i < len(d.terms) && strings.HasPrefix(d.terms[i].Term, prefix); i++ {
+		matches = append(matches, d.terms[i])
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Count > matches[j].Count })
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+// suggestCorrections proposes corrected versions of query by replacing
+// words missing from the dictionary with their closest known terms.
+func (d *termDictionary) suggestCorrections(query string, limit int) []string {
+	tokens := tokenize(query)
+	corrections := make([][]string, len(tokens))
+	misspelled := false
+	for i, token := range tokens {
+		if len([]rune(token)) < 3 || d.count(token) > 0 {
+			continue
+		}
+
+		maxDistance := 2
+		if len([]rune(token)) <= 4 {
+			maxDistance = 1
+		}
+		corrections[i] = d.closest(token, maxDistance, limit)
+		misspelled = misspelled || len(corrections[i]) > 0
+	}
+	if !misspelled {
+		return nil
+	}
+
+	var suggestions []string
+	for n := 0; n < limit; n++ {
+		words := make([]string, len(tokens))
+		for i, token := range tokens {
+			words[i] = token
+			if candidates := corrections[i]; len(candidates) > 0 {
+				words[i] = candidates[min(n, len(candidates)-1)]
+			}
+		}
+
+		suggestion := strings.Join(words, " ")
+		if !slices.Contains(suggestions, suggestion) {
+			suggestions = append(suggestions, suggestion)
+		}
+	}
+
+	return suggestions
+}
+
+// complete returns up to limit terms starting with prefix as completions,
+// most frequent first.
+func (d *termDictionary) complete(prefix string, limit int) []domain.Completion {
+	entries := d.withPrefix(strings.ToLower(prefix), limit)
+	completions := make([]domain.Completion, 0, len(entries))
+	for _, entry := range entries {
+		completions = append(completions, domain.Completion{
+			Text:  entry.Term,
+			Type:  domain.CompletionTerm,
+			Count: entry.Count,
+		})
+	}
+	return completions
+}
+
+// levenshtein computes the edit distance between a and b, giving up with
+// maxDistance+1 as soon as the distance is known to exceed maxDistance.
+func levenshtein(a, b string, maxDistance int) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			rowMin = min(rowMin, curr[j])
+		}
+		if rowMin > maxDistance {
+			return maxDistance + 1
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// tokenize splits a query into lowercase words the same way the standard
+// analyzer would for the purpose of dictionary lookups.
+func tokenize(query string) []string {
+	return strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"bufio"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/anugrahsputra/go-quran-api/config"
+	"github.com/anugrahsputra/go-quran-api/internal/domain"
+)
+
+type fileTranslation struct {
+	info   domain.TranslationInfo
+	verses map[domain.VerseRef]string
+}
+
+type translationRepository struct {
+	translations map[string]*fileTranslation
+	infos        []domain.TranslationInfo
+}
+
+// NewTranslationRepository loads every "<language>.<name>.txt" file in
+// cfg.TranslationsDir. Files use the Tanzil text format: one
+// "surah|ayah|text" line per verse, with "#" comment lines that may carry
+// "Name:" and "Translator:" metadata. Translation IDs are the lowercased
+// file names and are looked up ignoring case. An empty cfg.TranslationsDir
+// leaves only the default translation; a directory without translation
+// files is an error.
+func NewTranslationRepository(cfg *config.Config) (domain.TranslationRepository, error) {
+	r := &translationRepository{translations: make(map[string]*fileTranslation)}
+
+	var paths []string
+	if cfg.TranslationsDir == "" {
+		log.Printf("TRANSLATIONS_DIR is not set; only the %s translation is available", domain.DefaultTranslation)
+	} else {
+		if info, err := os.Stat(cfg.TranslationsDir); err != nil || !info.IsDir() {
+			return nil, fmt.Errorf("TRANSLATIONS_DIR %s is not a directory", cfg.TranslationsDir)
+		}
+		var err error
+		paths, err = filepath.Glob(filepath.Join(cfg.TranslationsDir, "*.txt"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list translations: %w", err)
+		}
+		if len(paths) == 0 {
+			return nil, fmt.Errorf("TRANSLATIONS_DIR %s holds no <language>.<name>.txt translation files", cfg.TranslationsDir)
+		}
+	}
+
+	for _, path := range paths {
+		id := strings.ToLower(strings.TrimSuffix(filepath.Base(path), ".txt"))
+		if id == domain.DefaultTranslation {
+			continue
+		}
+		if _, ok := r.translations[id]; ok {
+			return nil, fmt.Errorf("translation %s is in more than one file", id)
+		}
+
+		translation, err := loadTranslationFile(id, path)
+		if err != nil {
+			return nil, err
+		}
+		r.translations[id] = translation
+		log.Printf("Loaded translation %s (%d verses)", id, len(translation.verses))
+	}
+
+	r.infos = append(r.infos, domain.TranslationInfo{
+		ID:       domain.DefaultTranslation,
+		Language: "id",
+		Name:     "Terjemahan Kemenag",
+	})
+	ids := make([]string, 0, len(r.translations))
+	for id := range r.translations {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		r.infos = append(r.infos, r.translations[id].info)
+	}
+
+	return r, nil
+}
+
+func loadTranslationFile(id, path string) (*fileTranslation, error) {
+	language, _, ok := strings.Cut(id, ".")
+	if !ok || language == "" {
+		return nil, fmt.Errorf("invalid translation file name %s: expected <language>.<name>.txt", filepath.Base(path))
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open translation %s: %w", id, err)
+	}
+	defer file.Close()
+
+	translation := &fileTranslation{
+		info:   domain.TranslationInfo{ID: id, Language: language, Name: id},
+		verses: make(map[domain.VerseRef]string),
+	}
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if comment, ok := strings.CutPrefix(line, "#"); ok {
+			key, value, ok := strings.Cut(comment, ":")
+			switch strings.ToLower(strings.TrimSpace(key)) {
+			case "name":
+				if ok {
+					translation.info.Name = strings.TrimSpace(value)
+				}
+			case "translator":
+				if ok {
+					translation.info.Translator = strings.TrimSpace(value)
+				}
+			}
+			continue
+		}
+
+		parts := strings.SplitN(line, "|", 3)
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("translation %s line %d: expected surah|ayah|text", id, lineNumber)
+		}
+		surah, surahErr := strconv.Atoi(parts[0])
+		ayah, ayahErr := strconv.Atoi(parts[1])
+		if surahErr != nil || ayahErr != nil || surah < 1 || surah > 114 || ayah < 1 {
+			return nil, fmt.Errorf("translation %s line %d: invalid verse %s:%s", id, lineNumber, parts[0], parts[1])
+		}
+		translation.verses[domain.VerseRef{Surah: surah, Ayah: ayah}] = parts[2]
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read translation %s: %w", id, err)
+	}
+
+	return translation, nil
+}
+
+func (r *translationRepository) List() []domain.TranslationInfo {
+	return r.infos
+}
+
+func (r *translationRepository) Get(id string) (domain.TranslationInfo, bool) {
+	id = strings.ToLower(id)
+	if id == domain.DefaultTranslation {
+		return r.infos[0], true
+	}
+	translation, ok := r.translations[id]
+	if !ok {
+		return domain.TranslationInfo{}, false
+	}
+	return translation.info, true
+}
+
+func (r *translationRepository) Verse(id string, ref domain.VerseRef) (string, bool) {
+	translation, ok := r.translations[strings.ToLower(id)]
+	if !ok {
+		return "", false
+	}
+	text, ok := translation.verses[ref]
+	return text, ok
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/anugrahsputra/go-quran-api/config"
+	"github.com/anugrahsputra/go-quran-api/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranslationRepository(t *testing.T) {
+	dir := t.TempDir()
+	content := "1|1|In the name of Allah, the Entirely Merciful, the Especially Merciful.\n" +
+		"2|255|Allah - there is no deity except Him, the Ever-Living, the Sustainer of existence.\n" +
+		"\n#  Name: Saheeh International\n#  Translator: Saheeh International\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "en.Sahih.txt"), []byte(content), 0o644))
+
+	repo, err := NewTranslationRepository(&config.Config{TranslationsDir: dir})
+	require.NoError(t, err)
+
+	assert.Equal(t, []domain.TranslationInfo{
+		{ID: domain.DefaultTranslation, Language: "id", Name: "Terjemahan Kemenag"},
+		{ID: "en.sahih", Language: "en", Name: "Saheeh International", Translator: "Saheeh International"},
+	}, repo.List())
+
+	_, ok := repo.Get("EN.Sahih")
+	assert.True(t, ok)
+	text, ok := repo.Verse("en.SAHIH", domain.VerseRef{Surah: 2, Ayah: 255})
+	require.True(t, ok)
+	assert.Contains(t, text, "there is no deity except Him")
+
+	_, ok = repo.Verse("en.sahih", domain.VerseRef{Surah: 2, Ayah: 256})
+	assert.False(t, ok)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "en.broken.txt"), []byte("2:255 Allah\n"), 0o644))
+	_, err = NewTranslationRepository(&config.Config{TranslationsDir: dir})
+	assert.Error(t, err)
+}
+
+func TestTranslationRepository_Dir(t *testing.T) {
+	repo, err := NewTranslationRepository(&config.Config{})
+	require.NoError(t, err)
+	assert.Len(t, repo.List(), 1)
+
+	_, err = NewTranslationRepository(&config.Config{TranslationsDir: t.TempDir()})
+	assert.ErrorContains(t, err, "holds no")
+
+	_, err = NewTranslationRepository(&config.Config{TranslationsDir: filepath.Join(t.TempDir(), "missing")})
+	assert.ErrorContains(t, err, "is not a directory")
+}
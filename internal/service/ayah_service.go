@@ -13,22 +13,41 @@ import (
 )
 
 type AyahService interface {
-	GetAyah(ctx context.Context, id int) (dto.DetailAyahResp, error)
+	GetAyah(ctx context.Context, id int, translation string) (dto.DetailAyahResp, error)
 }
 
 type ayahService struct {
-	repo        domain.AyahRepository
-	redisClient *redis.Client
+	repo         domain.AyahRepository
+	redisClient  *redis.Client
+	translations ITranslationService
 }
 
-func NewAyahService(r domain.AyahRepository, rc *redis.Client) AyahService {
+func NewAyahService(r domain.AyahRepository, rc *redis.Client, ts ITranslationService) AyahService {
 	return &ayahService{
-		repo:        r,
-		redisClient: rc,
+		repo:         r,
+		redisClient:  rc,
+		translations: ts,
 	}
 }
 
-func (s *ayahService) GetAyah(ctx context.Context, id int) (dto.DetailAyahResp, error) {
+// GetAyah returns a verse with its text in translation, which must be a
+// resolved translation ID.
+func (s *ayahService) GetAyah(ctx context.Context, id int, translation string) (dto.DetailAyahResp, error) {
+	response, err := s.getAyah(ctx, id)
+	if err != nil {
+		return response, err
+	}
+
+	response.TranslationID = domain.DefaultTranslation
+	if translation != "" && translation != domain.DefaultTranslation {
+		response.TranslationID = translation
+		response.Translation = s.translations.Verse(translation, domain.VerseRef{Surah: response.SurahID, Ayah: response.Ayah})
+	}
+
+	return response, nil
+}
+
+func (s *ayahService) getAyah(ctx context.Context, id int) (dto.DetailAyahResp, error) {
 	cacheKey := fmt.Sprintf("quran:ayah:detail:%d", id)
 
 	if s.redisClient != nil {
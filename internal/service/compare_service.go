@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+
+	"github.com/anugrahsputra/go-quran-api/internal/delivery/dto"
+	"github.com/anugrahsputra/go-quran-api/internal/domain"
+)
+
+type CompareService interface {
+	// Compare returns a page of a surah with each verse in every given
+	// translation, paged like SurahService.GetSurahDetail.
+	Compare(ctx context.Context, surah int, page int, limit int, translations []domain.TranslationInfo) (dto.CompareData, int, int, error)
+}
+
+type compareService struct {
+	surahs       SurahService
+	translations ITranslationService
+}
+
+func NewCompareService(ss SurahService, ts ITranslationService) CompareService {
+	return &compareService{
+		surahs:       ss,
+		translations: ts,
+	}
+}
+
+func (s *compareService) Compare(ctx context.Context, surah int, page int, limit int, translations []domain.TranslationInfo) (dto.CompareData, int, int, error) {
+	detail, totalVerses, totalPages, err := s.surahs.GetSurahDetail(ctx, surah, page, limit, domain.DefaultTranslation)
+	if err != nil {
+		return dto.CompareData{}, totalVerses, totalPages, err
+	}
+
+	response := dto.CompareData{
+		SurahID:      detail.SurahID,
+		Arabic:       detail.Arabic,
+		Latin:        detail.Latin,
+		Location:     detail.Location,
+		Translations: translations,
+		Verses:       make([]dto.CompareVerse, len(detail.Verses)),
+	}
+
+	for i, verse := range detail.Verses {
+		texts := make(map[string]string, len(translations))
+		ref := domain.VerseRef{Surah: detail.SurahID, Ayah: verse.Ayah}
+		for _, translation := range translations {
+			if translation.ID == domain.DefaultTranslation {
+				texts[translation.ID] = verse.Translation
+			} else {
+				texts[translation.ID] = s.translations.Verse(translation.ID, ref)
+			}
+		}
+
+		response.Verses[i] = dto.CompareVerse{
+			Id:           verse.Id,
+			Ayah:         verse.Ayah,
+			Arabic:       verse.Arabic,
+			Latin:        verse.Latin,
+			Translations: texts,
+		}
+	}
+
+	return response, totalVerses, totalPages, nil
+}
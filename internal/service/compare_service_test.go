@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/anugrahsputra/go-quran-api/config"
+	"github.com/anugrahsputra/go-quran-api/internal/delivery/dto"
+	"github.com/anugrahsputra/go-quran-api/internal/domain"
+	"github.com/anugrahsputra/go-quran-api/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubSurahService struct {
+	detail dto.SurahDetailData
+}
+
+func (s *stubSurahService) GetListSurah(ctx context.Context) ([]dto.SurahResp, error) {
+	return nil, nil
+}
+
+func (s *stubSurahService) GetSurahDetail(ctx context.Context, id int, page int, limit int, translation string) (dto.SurahDetailData, int, int, error) {
+	return s.detail, 7, 4, nil
+}
+
+func TestCompareService(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "en.sahih.txt"), []byte("1|1|In the name of Allah\n"), 0o644))
+	translationRepo, err := repository.NewTranslationRepository(&config.Config{TranslationsDir: dir})
+	require.NoError(t, err)
+	ts := NewTranslationService(translationRepo)
+
+	surahs := &stubSurahService{detail: dto.SurahDetailData{
+		SurahID: 1,
+		Latin:   "Al-Fatihah",
+		Verses: []dto.Verse{
+			{Id: 1, Ayah: 1, Arabic: "بِسْمِ اللّٰهِ", Translation: "Dengan nama Allah"},
+			{Id: 2, Ayah: 2, Arabic: "اَلْحَمْدُ لِلّٰهِ", Translation: "Segala puji bagi Allah"},
+		},
+	}}
+
+	sahih, err := ts.Resolve("", "en-sahih")
+	require.NoError(t, err)
+	kemenag, err := ts.Resolve("", "")
+	require.NoError(t, err)
+
+	data, totalVerses, totalPages, err := NewCompareService(surahs, ts).Compare(context.Background(), 1, 1, 2, []domain.TranslationInfo{sahih, kemenag})
+	require.NoError(t, err)
+	assert.Equal(t, 7, totalVerses)
+	assert.Equal(t, 4, totalPages)
+	assert.Equal(t, "Al-Fatihah", data.Latin)
+	assert.Equal(t, []domain.TranslationInfo{sahih, kemenag}, data.Translations)
+	require.Len(t, data.Verses, 2)
+	assert.Equal(t, map[string]string{"en.sahih": "In the name of Allah", "id.kemenag": "Dengan nama Allah"}, data.Verses[0].Translations)
+	assert.Equal(t, map[string]string{"en.sahih": "", "id.kemenag": "Segala puji bagi Allah"}, data.Verses[1].Translations)
+}
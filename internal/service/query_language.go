@@ -0,0 +1,56 @@
+package service
+
+import (
+	"strings"
+	"unicode"
+)
+
+// languageMarkers are frequent words that are rare in the other supported
+// languages, used to tell an English query from an Indonesian one.
+var languageMarkers = map[string]map[string]struct{}{
+	"en": wordSet("the", "of", "and", "to", "is", "are", "was", "were", "be", "who", "whom", "what", "which",
+		"that", "this", "those", "these", "with", "from", "for", "upon", "you", "your", "he", "his", "him",
+		"they", "their", "them", "we", "our", "not", "will", "shall", "have", "has", "believers",
+		"prayer", "patience", "mercy", "paradise", "hell", "god", "lord", "day", "people", "charity"),
+	"id": wordSet("yang", "dan", "di", "ke", "dari", "tidak", "itu", "ini", "dengan", "untuk", "orang", "mereka",
+		"kamu", "kami", "kita", "akan", "adalah", "pada", "dalam", "atau", "bagi", "apa", "siapa", "telah",
+		"sesungguhnya", "hari", "tuhan", "beriman", "sabar", "salat", "shalat", "surga", "neraka", "rahmat",
+		"sedekah", "manusia", "bertakwa"),
+}
+
+func wordSet(words ...string) map[string]struct{} {
+	set := make(map[string]struct{}, len(words))
+	for _, word := range words {
+		set[word] = struct{}{}
+	}
+	return set
+}
+
+// DetectLanguage guesses whether text is English ("en") or Indonesian
+// ("id") from its marker words. It returns "" when neither language has more
+// markers, which is common for one-word queries.
+func DetectLanguage(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+
+	best, bestCount, tie := "", 0, false
+	for language, markers := range languageMarkers {
+		count := 0
+		for _, word := range words {
+			if _, ok := markers[word]; ok {
+				count++
+			}
+		}
+		switch {
+		case count > bestCount:
+			best, bestCount, tie = language, count, false
+		case count == bestCount && count > 0:
+			tie = true
+		}
+	}
+	if tie {
+		return ""
+	}
+	return best
+}
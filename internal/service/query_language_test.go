@@ -0,0 +1,28 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"the reward of patience", "en"},
+		{"those who believe", "en"},
+		{"orang yang beriman", "id"},
+		{"pahala orang sabar", "id"},
+		{"zakat", ""},
+		{"the orang", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			assert.Equal(t, tt.want, DetectLanguage(tt.text))
+		})
+	}
+}
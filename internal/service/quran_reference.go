@@ -0,0 +1,245 @@
+package service
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/anugrahsputra/go-quran-api/internal/domain"
+)
+
+type surahInfo struct {
+	Name    string
+	NumAyah int
+}
+
+// surahTable follows the Kemenag transliteration, indexed by surah number - 1.
+var surahTable = []surahInfo{
+	{"Al-Fatihah", 7}, {"Al-Baqarah", 286}, {"Ali 'Imran", 200}, {"An-Nisa'", 176},
+	{"Al-Ma'idah", 120}, {"Al-An'am", 165}, {"Al-A'raf", 206}, {"Al-Anfal", 75},
+	{"At-Taubah", 129}, {"Yunus", 109}, {"Hud", 123}, {"Yusuf", 111},
+	{"Ar-Ra'd", 43}, {"Ibrahim", 52}, {"Al-Hijr", 99}, {"An-Nahl", 128},
+	{"Al-Isra'", 111}, {"Al-Kahf", 110}, {"Maryam", 98}, {"Taha", 135},
+	{"Al-Anbiya'", 112}, {"Al-Hajj", 78}, {"Al-Mu'minun", 118}, {"An-Nur", 64},
+	{"Al-Furqan", 77}, {"Asy-Syu'ara'", 227}, {"An-Naml", 93}, {"Al-Qasas", 88},
+	{"Al-'Ankabut", 69}, {"Ar-Rum", 60}, {"Luqman", 34}, {"As-Sajdah", 30},
+	{"Al-Ahzab", 73}, {"Saba'", 54}, {"Fatir", 45}, {"Yasin", 83},
+	{"As-Saffat", 182}, {"Sad", 88}, {"Az-Zumar", 75}, {"Gafir", 85},
+	{"Fussilat", 54}, {"Asy-Syura", 53}, {"Az-Zukhruf", 89}, {"Ad-Dukhan", 59},
+	{"Al-Jasiyah", 37}, {"Al-Ahqaf", 35}, {"Muhammad", 38}, {"Al-Fath", 29},
+	{"Al-Hujurat", 18}, {"Qaf", 45}, {"Az-Zariyat", 60}, {"At-Tur", 49},
+	{"An-Najm", 62}, {"Al-Qamar", 55}, {"Ar-Rahman", 78}, {"Al-Waqi'ah", 96},
+	{"Al-Hadid", 29}, {"Al-Mujadilah", 22}, {"Al-Hasyr", 24}, {"Al-Mumtahanah", 13},
+	{"As-Saff", 14}, {"Al-Jumu'ah", 11}, {"Al-Munafiqun", 11}, {"At-Tagabun", 18},
+	{"At-Talaq", 12}, {"At-Tahrim", 12}, {"Al-Mulk", 30}, {"Al-Qalam", 52},
+	{"Al-Haqqah", 52}, {"Al-Ma'arij", 44}, {"Nuh", 28}, {"Al-Jinn", 28},
+	{"Al-Muzzammil", 20}, {"Al-Muddassir", 56}, {"Al-Qiyamah", 40}, {"Al-Insan", 31},
+	{"Al-Mursalat", 50}, {"An-Naba'", 40}, {"An-Nazi'at", 46}, {"'Abasa", 42},
+	{"At-Takwir", 29}, {"Al-Infitar", 19}, {"Al-Mutaffifin", 36}, {"Al-Insyiqaq", 25},
+	{"Al-Buruj", 22}, {"At-Tariq", 17}, {"Al-A'la", 19}, {"Al-Gasyiyah", 26},
+	{"Al-Fajr", 30}, {"Al-Balad", 20}, {"Asy-Syams", 15}, {"Al-Lail", 21},
+	{"Ad-Duha", 11}, {"Asy-Syarh", 8}, {"At-Tin", 8}, {"Al-'Alaq", 19},
+	{"Al-Qadr", 5}, {"Al-Bayyinah", 8}, {"Az-Zalzalah", 8}, {"Al-'Adiyat", 11},
+	{"Al-Qari'ah", 11}, {"At-Takasur", 8}, {"Al-'Asr", 3}, {"Al-Humazah", 9},
+	{"Al-Fil", 5}, {"Quraisy", 4}, {"Al-Ma'un", 7}, {"Al-Kausar", 3},
+	{"Al-Kafirun", 6}, {"An-Nasr", 3}, {"Al-Lahab", 5}, {"Al-Ikhlas", 4},
+	{"Al-Falaq", 5}, {"An-Nas", 6},
+}
+
+// surahAliases covers spellings that normalizeName cannot derive from the
+// Kemenag transliteration.
+var surahAliases = map[string]int{
+	"mukmin":      40,
+	"almukmin":    40,
+	"ghafir":      40,
+	"thaha":       20,
+	"fusilat":     41,
+	"dzariyat":    51,
+	"taghabun":    64,
+	"ghasyiyah":   88,
+	"alamnasyrah": 94,
+	"insyirah":    94,
+	"takatsur":    102,
+	"kautsar":     108,
+}
+
+// wellKnownVerses maps popular verse names, normalized and with any
+// "ayatul"/"ayatu"/"ayatal" folded into "ayat", to their reference.
+var wellKnownVerses = map[string]domain.VerseRef{
+	"ayatkursi":       {Surah: 2, Ayah: 255},
+	"ayatkursiy":      {Surah: 2, Ayah: 255},
+	"ayatseribudinar": {Surah: 65, Ayah: 2},
+	"ayat1000dinar":   {Surah: 65, Ayah: 2},
+	"ayatnur":         {Surah: 24, Ayah: 35},
+	"amanarasul":      {Surah: 2, Ayah: 285},
+	"basmalah":        {Surah: 1, Ayah: 1},
+	"bismilah":        {Surah: 1, Ayah: 1},
+	"ayatpuasa":       {Surah: 2, Ayah: 183},
+	"ayatwudu":        {Surah: 5, Ayah: 6},
+	"ayatwudhu":       {Surah: 5, Ayah: 6},
+	"ayatmubahalah":   {Surah: 3, Ayah: 61},
+	"ayattathir":      {Surah: 33, Ayah: 33},
+}
+
+var (
+	numericRefPattern  = regexp.MustCompile(`^(\d{1,3})\s*[:.\s]\s*(\d{1,3})$`)
+	namedRefPattern    = regexp.MustCompile(`^(.+?)\s*[:\s]\s*(?:ayat\s+|ayah\s+|verse\s+)?(\d{1,3})$`)
+	refPrefixPattern   = regexp.MustCompile(`^(?:q\.?\s?s\.?|surah|surat)\s+`)
+	versePrefixPattern = regexp.MustCompile(`^ayat(?:ul|u|al)?`)
+)
+
+var surahNameIndex = buildSurahNameIndex()
+
+func buildSurahNameIndex() map[string]int {
+	idx := make(map[string]int, len(surahTable)*2+len(surahAliases))
+	for i, s := range surahTable {
+		name := letters(s.Name)
+		idx[collapse(name)] = i + 1
+		if stripped := stripArticle(name); stripped != name {
+			idx[collapse(stripped)] = i + 1
+		}
+	}
+	for alias, number := range surahAliases {
+		idx[alias] = number
+	}
+	return idx
+}
+
+// letters lowercases s, drops everything but letters and digits and folds
+// the English "sh" into the Indonesian "sy".
+func letters(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return strings.ReplaceAll(b.String(), "sh", "sy")
+}
+
+// collapse squeezes doubled letters so that "muzzammil" and "muzamil"
+// compare equal.
+func collapse(s string) string {
+	out := make([]rune, 0, len(s))
+	for _, r := range s {
+		if len(out) > 0 && out[len(out)-1] == r && unicode.IsLetter(r) {
+			continue
+		}
+		out = append(out, r)
+	}
+	return string(out)
+}
+
+func normalizeName(s string) string {
+	return collapse(letters(s))
+}
+
+func stripArticle(name string) string {
+	for _, article := range []string{"asy", "al", "an", "ar", "as", "at", "az", "ad"} {
+		rest := strings.TrimPrefix(name, article)
+		if rest != name && len(rest) >= 3 {
+			return rest
+		}
+	}
+	return name
+}
+
+// SurahName returns the Kemenag transliteration of a surah name.
+func SurahName(number int) string {
+	if number < 1 || number > len(surahTable) {
+		return ""
+	}
+	return surahTable[number-1].Name
+}
+
+// LookupSurah resolves a surah by its number or any of its common spellings.
+func LookupSurah(s string) (int, bool) {
+	s = strings.TrimSpace(s)
+	if n, err := strconv.Atoi(s); err == nil {
+		return n, n >= 1 && n <= len(surahTable)
+	}
+
+	name := letters(s)
+	if n, ok := surahNameIndex[collapse(name)]; ok {
+		return n, true
+	}
+	if n, ok := surahNameIndex[collapse(stripArticle(name))]; ok {
+		return n, true
+	}
+	return 0, false
+}
+
+// VerseByNumber converts an absolute verse number (1-6236, in mushaf order)
+// to a reference.
+func VerseByNumber(number int) (domain.VerseRef, bool) {
+	if number < 1 {
+		return domain.VerseRef{}, false
+	}
+	for i, s := range surahTable {
+		if number <= s.NumAyah {
+			return domain.VerseRef{Surah: i + 1, Ayah: number}, true
+		}
+		number -= s.NumAyah
+	}
+	return domain.VerseRef{}, false
+}
+
+// SurahsWithPrefix returns the numbers of surahs whose name, with or without
+// its article, starts with prefix.
+func SurahsWithPrefix(prefix string, limit int) []int {
+	name := letters(prefix)
+	if name == "" {
+		return nil
+	}
+	collapsed := collapse(name)
+
+	var numbers []int
+	for i, s := range surahTable {
+		full := letters(s.Name)
+		if strings.HasPrefix(collapse(full), collapsed) || strings.HasPrefix(collapse(stripArticle(full)), collapsed) {
+			numbers = append(numbers, i+1)
+			if len(numbers) == limit {
+				break
+			}
+		}
+	}
+	return numbers
+}
+
+// ParseReference recognizes queries that point at a single verse, such as
+// "2:255", "QS 2:255", "albaqarah 255", "al-baqarah ayat 255" or a well-known
+// name like "ayat kursi".
+func ParseReference(q string) (domain.VerseRef, bool) {
+	q = strings.ToLower(strings.TrimSpace(q))
+	if q == "" {
+		return domain.VerseRef{}, false
+	}
+
+	if ref, ok := wellKnownVerses[versePrefixPattern.ReplaceAllString(normalizeName(q), "ayat")]; ok {
+		return ref, true
+	}
+
+	q = refPrefixPattern.ReplaceAllString(q, "")
+
+	var (
+		surah int
+		ok    bool
+		m     []string
+	)
+	if m = numericRefPattern.FindStringSubmatch(q); m != nil {
+		surah, ok = LookupSurah(m[1])
+	} else if m = namedRefPattern.FindStringSubmatch(q); m != nil {
+		surah, ok = LookupSurah(m[1])
+	}
+	if !ok {
+		return domain.VerseRef{}, false
+	}
+
+	ayah, err := strconv.Atoi(m[2])
+	if err != nil || ayah < 1 || ayah > surahTable[surah-1].NumAyah {
+		return domain.VerseRef{}, false
+	}
+
+	return domain.VerseRef{Surah: surah, Ayah: ayah}, true
+}
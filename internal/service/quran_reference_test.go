@@ -0,0 +1,62 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/anugrahsputra/go-quran-api/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseReference(t *testing.T) {
+	tests := []struct {
+		query string
+		want  domain.VerseRef
+		ok    bool
+	}{
+		{"2:255", domain.VerseRef{Surah: 2, Ayah: 255}, true},
+		{"QS 2:255", domain.VerseRef{Surah: 2, Ayah: 255}, true},
+		{"QS. Al-Baqarah: 255", domain.VerseRef{Surah: 2, Ayah: 255}, true},
+		{"albaqarah 255", domain.VerseRef{Surah: 2, Ayah: 255}, true},
+		{"baqarah ayat 255", domain.VerseRef{Surah: 2, Ayah: 255}, true},
+		{"ayat kursi", domain.VerseRef{Surah: 2, Ayah: 255}, true},
+		{"Ayatul Kursi", domain.VerseRef{Surah: 2, Ayah: 255}, true},
+		{"surah yasin 1", domain.VerseRef{Surah: 36, Ayah: 1}, true},
+		{"at-taubah 5", domain.VerseRef{Surah: 9, Ayah: 5}, true},
+		{"ash-shams 1", domain.VerseRef{Surah: 91, Ayah: 1}, true},
+		{"al muzammil 4", domain.VerseRef{Surah: 73, Ayah: 4}, true},
+		{"2:287", domain.VerseRef{}, false},
+		{"115:1", domain.VerseRef{}, false},
+		{"orang beriman", domain.VerseRef{}, false},
+		{"orang beriman 2", domain.VerseRef{}, false},
+		{"", domain.VerseRef{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			got, ok := ParseReference(tt.query)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestVerseByNumber(t *testing.T) {
+	tests := []struct {
+		number int
+		want   domain.VerseRef
+		ok     bool
+	}{
+		{1, domain.VerseRef{Surah: 1, Ayah: 1}, true},
+		{8, domain.VerseRef{Surah: 2, Ayah: 1}, true},
+		{262, domain.VerseRef{Surah: 2, Ayah: 255}, true},
+		{6236, domain.VerseRef{Surah: 114, Ayah: 6}, true},
+		{0, domain.VerseRef{}, false},
+		{6237, domain.VerseRef{}, false},
+	}
+
+	for _, tt := range tests {
+		ref, ok := VerseByNumber(tt.number)
+		assert.Equal(t, tt.ok, ok, tt.number)
+		assert.Equal(t, tt.want, ref, tt.number)
+	}
+}
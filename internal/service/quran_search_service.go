@@ -2,9 +2,12 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"runtime"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"sync/atomic"
@@ -13,27 +16,65 @@ import (
 	"github.com/anugrahsputra/go-quran-api/internal/domain"
 )
 
+const (
+	// suggestionThreshold is the result count below which a search also
+	// returns "did you mean" suggestions.
+	suggestionThreshold = 3
+	maxSuggestions      = 3
+)
+
 type IQuranSearchService interface {
 	IndexQuran() error
-	Search(query string, page, limit int) ([]domain.SearchedAyah, int, error)
+	Search(params domain.SearchParams) (domain.SearchResults, error)
+	Export(params domain.SearchParams, emit func(domain.SearchHit) error) (int, error)
+	FindReference(query, translation string) (*domain.SearchHit, error)
+	// Related returns a verse and the verses most similar to it, or a nil
+	// verse when it is not indexed.
+	Related(ref domain.VerseRef, limit int, translation string) (*domain.SearchHit, []domain.RelatedVerse, error)
+	Suggest(prefix string, limit int) ([]domain.Completion, error)
+	SearchTafsir(params domain.SearchParams) (domain.TafsirResults, error)
+	ReloadSynonyms() (int, error)
+	SearchCacheStats() SearchCacheStats
+	SearchAnalytics(window string, limit int) (SearchAnalyticsReport, error)
 }
 
 type quranSearchService struct {
-	quranRepo  domain.SurahRepository
-	ayahRepo   domain.AyahRepository
-	searchRepo domain.QuranSearchRepository
-	isIndexing atomic.Bool
+	quranRepo    domain.SurahRepository
+	ayahRepo     domain.AyahRepository
+	searchRepo   domain.QuranSearchRepository
+	tafsirRepo   domain.TafsirSearchRepository
+	synonyms     *SynonymStore
+	translations ITranslationService
+	cache        *SearchCache
+	analytics    *SearchAnalytics
+	isIndexing   atomic.Bool
 }
 
-func NewQuranSearchService(qr domain.SurahRepository, ar domain.AyahRepository, sr domain.QuranSearchRepository) IQuranSearchService {
-	return &quranSearchService{quranRepo: qr, ayahRepo: ar, searchRepo: sr}
+func NewQuranSearchService(qr domain.SurahRepository, ar domain.AyahRepository, sr domain.QuranSearchRepository, tr domain.TafsirSearchRepository, synonyms *SynonymStore, ts ITranslationService, cache *SearchCache, analytics *SearchAnalytics) IQuranSearchService {
+	return &quranSearchService{quranRepo: qr, ayahRepo: ar, searchRepo: sr, tafsirRepo: tr, synonyms: synonyms, translations: ts, cache: cache, analytics: analytics}
 }
 
-func (s *quranSearchService) IndexQuran() error {
+// IndexQuran fetches every verse and indexes it into a new index, which
+// replaces the searched one when indexing ends, so that mapping changes
+// take effect.
+func (s *quranSearchService) IndexQuran() (err error) {
 	if !s.isIndexing.CompareAndSwap(false, true) {
 		return fmt.Errorf("indexing is already in progress")
 	}
 	defer s.isIndexing.Store(false)
+	defer s.cache.invalidate()
+
+	previousCount, _ := s.searchRepo.GetDocCount()
+	finishRebuild, err := s.startRebuild()
+	if err != nil {
+		return err
+	}
+	keep := false
+	defer func() {
+		if finishErr := finishRebuild(keep); finishErr != nil {
+			err = errors.Join(err, fmt.Errorf("failed to replace the search indexes: %w", finishErr))
+		}
+	}()
 
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
 	defer cancel()
@@ -47,6 +88,8 @@ func (s *quranSearchService) IndexQuran() error {
 
 	var (
 		allAyahs         []domain.SearchedAyah
+		allParagraphs    []domain.TafsirParagraph
+		totalParagraphs  int
 		successCount     int
 		failureCount     int
 		totalAyahs       int
@@ -54,6 +97,11 @@ func (s *quranSearchService) IndexQuran() error {
 		startTime        = time.Now()
 	)
 
+	var extraTranslations []domain.TranslationInfo
+	if s.translations != nil {
+		extraTranslations = s.translations.List()[1:]
+	}
+
 	log.Printf("Starting Quran indexing process for %d surahs...", totalSurahs)
 
 	for i := 1; i <= totalSurahs; i++ {
@@ -112,13 +160,50 @@ func (s *quranSearchService) IndexQuran() error {
 				Latin:       verse.Latin,
 				Translation: verse.Translation,
 				Tafsir:      tafsirData.Tafsir.Tahlili,
+				Wajiz:       tafsirData.Tafsir.Wajiz,
+				SababNuzul:  tafsirData.Tafsir.SababNuzul,
+				Kosakata:    tafsirData.Tafsir.Kosakata,
+				Conclusion:  tafsirData.Tafsir.Conclusion,
+				Kitabah:     verse.Kitabah,
+				SurahName:   verse.Surah.Latin,
 				Topic:       tafsirData.Tafsir.ThemeGroup,
+				Juz:         verse.Juz,
+				Page:        verse.Page,
+				Location:    NormalizeLocation(verse.Surah.Location),
+			}
+			if ayah.Location == "" {
+				ayah.Location = verse.Surah.Location
+			}
+
+			for _, translation := range extraTranslations {
+				ref := domain.VerseRef{Surah: verse.SurahID, Ayah: verse.Ayah}
+				if text := s.translations.Verse(translation.ID, ref); text != "" {
+					if ayah.Translations == nil {
+						ayah.Translations = make(map[string]string, len(extraTranslations))
+					}
+					ayah.Translations[translation.ID] = text
+				}
 			}
 
 			if ayah.Translation == "" {
 				emptyTranslation++
 			}
 
+			if s.tafsirRepo != nil {
+				for n, paragraph := range SplitTafsir(ayah.Tafsir) {
+					allParagraphs = append(allParagraphs, domain.TafsirParagraph{
+						SurahNumber: ayah.SurahNumber,
+						AyahNumber:  ayah.AyahNumber,
+						Paragraph:   n + 1,
+						Tafsir:      paragraph,
+						Text:        ayah.Text,
+						Translation: ayah.Translation,
+						Juz:         ayah.Juz,
+						Location:    ayah.Location,
+					})
+				}
+			}
+
 			allAyahs = append(allAyahs, ayah)
 			surahAyahCount++
 			totalAyahs++
@@ -157,16 +242,28 @@ func (s *quranSearchService) IndexQuran() error {
 			log.Printf("Indexed batch %d: %d ayahs (up to surah %d)", batchNum, len(allAyahs), i)
 			allAyahs = allAyahs[:0]
 
+			if s.tafsirRepo != nil && len(allParagraphs) > 0 {
+				if err := s.tafsirRepo.Index(allParagraphs); err != nil {
+					return fmt.Errorf("failed to index tafsir paragraphs (up to surah %d): %w", i, err)
+				}
+				totalParagraphs += len(allParagraphs)
+				allParagraphs = allParagraphs[:0]
+			}
+
 			runtime.GC()
 		}
 	}
 
+	// An index missing failed surahs only replaces an empty one.
+	keep = failureCount == 0 || previousCount == 0
+
 	duration := time.Since(startTime)
 	log.Printf("Indexing completed!")
 	log.Printf("Statistics:")
 	log.Printf("  - Total surahs processed: %d/%d", successCount, totalSurahs)
 	log.Printf("  - Failed surahs: %d", failureCount)
 	log.Printf("  - Total ayahs indexed: %d", totalAyahs)
+	log.Printf("  - Total tafsir paragraphs indexed: %d", totalParagraphs)
 	log.Printf("  - Ayahs with empty translation: %d (%.1f%%)",
 		emptyTranslation, float64(emptyTranslation)/float64(totalAyahs)*100)
 	log.Printf("  - Total duration: %v", duration.Round(time.Second))
@@ -180,132 +277,416 @@ func (s *quranSearchService) IndexQuran() error {
 	return nil
 }
 
-func (s *quranSearchService) Search(q string, page, limit int) ([]domain.SearchedAyah, int, error) {
-	searchResult, err := s.searchRepo.Search(q, page, limit)
+// startRebuild starts rebuilding the verse and tafsir paragraph indexes,
+// returning the function that finishes both.
+func (s *quranSearchService) startRebuild() (func(keep bool) error, error) {
+	finishAyahs, err := s.searchRepo.Rebuild()
 	if err != nil {
-		return nil, 0, err
+		return nil, fmt.Errorf("failed to start rebuilding the search index: %w", err)
+	}
+	if s.tafsirRepo == nil {
+		return finishAyahs, nil
 	}
 
-	totalResults := int(searchResult.Total)
+	finishParagraphs, err := s.tafsirRepo.Rebuild()
+	if err != nil {
+		return nil, errors.Join(
+			fmt.Errorf("failed to start rebuilding the tafsir paragraph index: %w", err),
+			finishAyahs(false),
+		)
+	}
+	return func(keep bool) error {
+		return errors.Join(finishAyahs(keep), finishParagraphs(keep))
+	}, nil
+}
 
-	var ayahs []domain.SearchedAyah
-	for _, hit := range searchResult.Hits {
-		var surahNumber, ayahNumber int
-		var text, latin, translation string
+// Search returns a page of hits, from the cache when the same search was
+// run since the index last changed.
+func (s *quranSearchService) Search(params domain.SearchParams) (domain.SearchResults, error) {
+	start := time.Now()
+	key, results, ok := s.cache.get(params)
+	if !ok {
+		var err error
+		results, err = s.search(params)
+		if err != nil {
+			return results, err
+		}
+		s.cache.set(key, results)
+	}
 
-		fields := hit.Fields
-		if len(fields) == 0 {
-			log.Printf("Warning: No fields found in hit - ID: %s, Score: %f", hit.ID, hit.Score)
+	// Later pages of a search are not searches of their own.
+	if s.analytics != nil && params.Page <= 1 && params.Cursor == "" {
+		query := params.Query
+		if query == "" {
+			query = queryText(params.Clauses)
+		}
+		s.analytics.record(searchRecord{query: query, total: results.Total, latency: time.Since(start), cached: ok})
+	}
+	return results, nil
+}
 
-			parts := splitDocumentID(hit.ID)
-			if len(parts) == 2 {
-				if sn, err := strconv.Atoi(parts[0]); err == nil {
-					surahNumber = sn
-				}
-				if an, err := strconv.Atoi(parts[1]); err == nil {
-					ayahNumber = an
-				}
-				if surahNumber > 0 && ayahNumber > 0 {
-					ayahs = append(ayahs, domain.SearchedAyah{
-						SurahNumber: surahNumber,
-						AyahNumber:  ayahNumber,
-						Text:        "",
-						Latin:       "",
-						Translation: "",
-					})
-					log.Printf("Warning: Created minimal ayah from ID for %s", hit.ID)
-				}
-			}
-			continue
+func (s *quranSearchService) SearchCacheStats() SearchCacheStats {
+	return s.cache.Stats()
+}
+
+func (s *quranSearchService) SearchAnalytics(window string, limit int) (SearchAnalyticsReport, error) {
+	return s.analytics.Report(window, limit)
+}
+
+func (s *quranSearchService) search(params domain.SearchParams) (domain.SearchResults, error) {
+	params, err := s.prepareSearch(params)
+	if err != nil {
+		return domain.SearchResults{}, err
+	}
+
+	indexResults, err := s.searchRepo.Search(params)
+	if err != nil {
+		return domain.SearchResults{}, err
+	}
+
+	totalResults := indexResults.Total
+	results := domain.SearchResults{
+		Hits:          searchHits(indexResults),
+		Total:         totalResults,
+		QueryLanguage: params.QueryLanguage,
+		Facets:        nameFacets(indexResults.Facets),
+	}
+	if params.Explain {
+		results.QueryTree = indexResults.Query
+	}
+	if from, size := params.Window(); size > 0 && len(indexResults.Hits) == size {
+		// Without a cursor the total tells whether this is the last page.
+		if len(params.SearchAfter) > 0 || from+size < totalResults {
+			results.NextCursor = encodeCursor(params.Sort, indexResults.Hits[size-1].SortValues)
 		}
+	}
 
-		if sn, ok := fields["SurahNumber"]; ok {
-			switch v := sn.(type) {
-			case float64:
-				surahNumber = int(v)
-			case int:
-				surahNumber = v
-			case int64:
-				surahNumber = int(v)
-			case int32:
-				surahNumber = int(v)
-			}
+	if totalResults < suggestionThreshold {
+		suggestions, err := s.searchRepo.SpellingSuggestions(queryText(params.Clauses), maxSuggestions)
+		if err != nil {
+			log.Printf("Warning: Failed to build spelling suggestions for '%s': %v", params.Query, err)
 		}
+		results.Suggestions = suggestions
+	}
 
-		if an, ok := fields["AyahNumber"]; ok {
-			switch v := an.(type) {
-			case float64:
-				ayahNumber = int(v)
-			case int:
-				ayahNumber = v
-			case int64:
-				ayahNumber = int(v)
-			case int32:
-				ayahNumber = int(v)
+	return results, nil
+}
+
+// exportBatchSize is the number of hits Export reads from the index at a
+// time, the most the repository returns per search.
+const exportBatchSize = 100
+
+// Export passes every hit matching params to emit in mushaf order and
+// returns how many were emitted. Hits are read a batch at a time, so the
+// whole result set is never held in memory. Paging, sorting, highlights,
+// facets and explanations in params are ignored.
+func (s *quranSearchService) Export(params domain.SearchParams, emit func(domain.SearchHit) error) (int, error) {
+	params.Sort = []domain.SortField{{Field: domain.SortSurah}}
+	params.Page, params.Limit = 1, exportBatchSize
+	params.Cursor, params.SearchAfter = "", nil
+	params.Highlight, params.Facets, params.Explain = "", nil, false
+
+	params, err := s.prepareSearch(params)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for {
+		indexResults, err := s.searchRepo.Search(params)
+		if err != nil {
+			return count, err
+		}
+		for _, hit := range searchHits(indexResults) {
+			if err := emit(hit); err != nil {
+				return count, err
 			}
+			count++
+		}
+		if len(indexResults.Hits) < exportBatchSize {
+			return count, nil
 		}
+		params.SearchAfter = indexResults.Hits[len(indexResults.Hits)-1].SortValues
+	}
+}
 
-		if t, ok := fields["Text"]; ok {
-			if textStr, ok := t.(string); ok {
-				text = textStr
+// prepareSearch validates params and turns the query into the clauses,
+// translation weights and search-after values the repository searches.
+func (s *quranSearchService) prepareSearch(params domain.SearchParams) (domain.SearchParams, error) {
+	for _, clause := range params.Clauses {
+		if err := checkNumericClause(clause); err != nil {
+			return params, err
+		}
+	}
+	if params.Cursor != "" {
+		after, err := decodeCursor(params.Cursor, params.Sort)
+		if err != nil {
+			return params, err
+		}
+		params.SearchAfter = after
+	}
+	if params.Query != "" {
+		clauses, err := ParseSearchQuery(params.Query)
+		if err != nil {
+			// A reference such as "albaqarah:255" reads like an unknown field
+			// but is still a valid query.
+			if _, ok := ParseReference(params.Query); !ok {
+				return params, err
 			}
+			clauses = []domain.QueryClause{{Text: params.Query, Occur: domain.OccurShould}}
 		}
+		params.Clauses = append(clauses, params.Clauses...)
+	}
+	params.Clauses = resolveSurahClauses(applySectionHints(params.Clauses, params.Fields))
+	if params.Translation == "" {
+		params.TranslationBoosts, params.QueryLanguage = s.translationBoosts(params)
+	}
+	params.Clauses = s.synonyms.Expand(params.Clauses)
+	return params, nil
+}
 
-		if l, ok := fields["Latin"]; ok {
-			if latinStr, ok := l.(string); ok {
-				latin = latinStr
-			} else {
-				log.Printf("Warning: Latin field exists but is not a string, type: %T, value: %v", l, l)
-			}
+// searchHits converts the verses found by the index to full-text hits.
+func searchHits(indexResults *domain.IndexResults) []domain.SearchHit {
+	hits := make([]domain.SearchHit, 0, len(indexResults.Hits))
+	for _, hit := range indexResults.Hits {
+		hits = append(hits, domain.SearchHit{
+			SearchedAyah:       hit.SearchedAyah,
+			MatchType:          domain.MatchTypeFullText,
+			MatchedTranslation: hit.MatchedTranslation,
+			Highlights:         hit.Highlights,
+			Explanation:        hit.Explanation,
+		})
+	}
+	return hits
+}
+
+const (
+	// otherLanguageWeight scales translations that are not in the query
+	// language.
+	otherLanguageWeight = 0.3
+	// undetectedLanguageWeight scales translations other than the default
+	// one when the query language is unknown.
+	undetectedLanguageWeight = 0.8
+)
+
+// translationBoosts weights every available translation for a search that
+// did not select one, favouring translations in the query language, which
+// is detected unless given. It also returns that language.
+func (s *quranSearchService) translationBoosts(params domain.SearchParams) (map[string]float64, string) {
+	language := strings.ToLower(params.QueryLanguage)
+	if language == "" {
+		language = DetectLanguage(queryText(params.Clauses))
+	}
+	if s.translations == nil {
+		return nil, language
+	}
+
+	infos := s.translations.List()
+	if len(infos) < 2 {
+		return nil, language
+	}
+
+	known := slices.ContainsFunc(infos, func(info domain.TranslationInfo) bool {
+		return info.Language == language
+	})
+	boosts := make(map[string]float64, len(infos))
+	for _, info := range infos {
+		switch {
+		case known && info.Language == language:
+			boosts[info.ID] = 1.0
+		case known:
+			boosts[info.ID] = otherLanguageWeight
+		case info.ID == domain.DefaultTranslation:
+			boosts[info.ID] = 1.0
+		default:
+			boosts[info.ID] = undetectedLanguageWeight
 		}
+	}
+	return boosts, language
+}
 
-		if t, ok := fields["Translation"]; ok {
-			if translationStr, ok := t.(string); ok {
-				translation = translationStr
-			}
+// SearchTafsir finds the tafsir paragraphs matching params.Query and
+// params.Clauses, which may only target the tafsir field.
+func (s *quranSearchService) SearchTafsir(params domain.SearchParams) (domain.TafsirResults, error) {
+	if s.tafsirRepo == nil {
+		return domain.TafsirResults{}, errors.New("tafsir search is not configured")
+	}
+
+	if params.Query != "" {
+		clauses, err := ParseSearchQuery(params.Query)
+		if err != nil {
+			return domain.TafsirResults{}, err
+		}
+		params.Clauses = append(clauses, params.Clauses...)
+	}
+	for _, clause := range params.Clauses {
+		if clause.Field != "" && clause.Field != domain.FieldTafsir {
+			return domain.TafsirResults{}, syntaxError("field %q cannot be searched in tafsir paragraphs", clause.Field)
 		}
+	}
+	params.Clauses = s.synonyms.Expand(params.Clauses)
 
-		var tafsir string
-		if t, ok := fields["Tafsir"]; ok {
-			if tafsirStr, ok := t.(string); ok {
-				tafsir = tafsirStr
-			}
+	return s.tafsirRepo.Search(params)
+}
+
+// ReloadSynonyms rereads the synonym file used for query expansion.
+func (s *quranSearchService) ReloadSynonyms() (int, error) {
+	if s.synonyms == nil {
+		return 0, errors.New("synonym expansion is not configured")
+	}
+	groups, err := s.synonyms.Reload()
+	if err != nil {
+		return 0, err
+	}
+	s.cache.invalidate()
+	return groups, nil
+}
+
+func (s *quranSearchService) FindReference(q, translation string) (*domain.SearchHit, error) {
+	ref, ok := ParseReference(q)
+	if !ok {
+		return nil, nil
+	}
+
+	ayah, err := s.searchRepo.GetDocument(ref.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load ayah %s: %w", ref, err)
+	}
+	if ayah == nil {
+		return nil, nil
+	}
+
+	hit := &domain.SearchHit{
+		SearchedAyah: *ayah,
+		MatchType:    domain.MatchTypeReference,
+	}
+	if translation != "" && translation != domain.DefaultTranslation {
+		hit.Translation = s.translations.Verse(translation, ref)
+	}
+	return hit, nil
+}
+
+func (s *quranSearchService) Related(ref domain.VerseRef, limit int, translation string) (*domain.SearchHit, []domain.RelatedVerse, error) {
+	ayah, err := s.searchRepo.GetDocument(ref.String())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load ayah %s: %w", ref, err)
+	}
+	if ayah == nil {
+		return nil, nil, nil
+	}
+
+	source := &domain.SearchHit{
+		SearchedAyah: *ayah,
+		MatchType:    domain.MatchTypeReference,
+	}
+	// Related verses are listed without tafsir, so the source is too.
+	source.Tafsir, source.Wajiz, source.SababNuzul, source.Kosakata, source.Conclusion = "", "", "", "", ""
+
+	indexResults, err := s.searchRepo.Related(ref.String(), limit)
+	if err != nil {
+		return nil, nil, err
+	}
+	if indexResults == nil {
+		return nil, nil, nil
+	}
+
+	related := make([]domain.RelatedVerse, 0, len(indexResults.Hits))
+	for _, hit := range indexResults.Hits {
+		verse := domain.RelatedVerse{
+			SearchedAyah: hit.SearchedAyah,
+			Score:        hit.Score,
+			SharedTerms:  sharedTerms(hit.MatchedTerms),
+		}
+		if source.Topic != "" && strings.EqualFold(hit.Topic, source.Topic) {
+			verse.SharedTopic = hit.Topic
 		}
+		related = append(related, verse)
+	}
 
-		var topic string
-		if t, ok := fields["Topic"]; ok {
-			if topicStr, ok := t.(string); ok {
-				topic = topicStr
-			}
+	if translation != "" && translation != domain.DefaultTranslation {
+		source.Translation = s.translations.Verse(translation, ref)
+		for i := range related {
+			verseRef := domain.VerseRef{Surah: related[i].SurahNumber, Ayah: related[i].AyahNumber}
+			related[i].Translation = s.translations.Verse(translation, verseRef)
 		}
+	}
+
+	return source, related, nil
+}
 
-		if surahNumber > 0 && ayahNumber > 0 {
-			ayahs = append(ayahs, domain.SearchedAyah{
-				SurahNumber: surahNumber,
-				AyahNumber:  ayahNumber,
-				Text:        text,
-				Latin:       latin,
-				Translation: translation,
-				Tafsir:      tafsir,
-				Topic:       topic,
-			})
-		} else {
-			log.Printf("Warning: Skipping hit with incomplete data - ID: %s, SurahNumber: %v, AyahNumber: %v, Fields keys: %v",
-				hit.ID, fields["SurahNumber"], fields["AyahNumber"], getFieldKeys(fields))
+// maxSharedTerms caps the terms listed to explain a related verse.
+const maxSharedTerms = 5
+
+// sharedTerms lists the matched terms of a related verse, most occurrences
+// first.
+func sharedTerms(counts map[string]int) []string {
+	terms := make([]string, 0, len(counts))
+	for term := range counts {
+		terms = append(terms, term)
+	}
+	sort.Slice(terms, func(i, j int) bool {
+		if counts[terms[i]] != counts[terms[j]] {
+			return counts[terms[i]] > counts[terms[j]]
 		}
+		return terms[i] < terms[j]
+	})
+	if len(terms) > maxSharedTerms {
+		terms = terms[:maxSharedTerms]
+	}
+	return terms
+}
+
+// Suggest completes the last word of prefix from the index term dictionaries,
+// listing matching surah names first.
+func (s *quranSearchService) Suggest(prefix string, limit int) ([]domain.Completion, error) {
+	words := strings.Fields(prefix)
+	if len(words) == 0 {
+		return nil, nil
 	}
 
-	return ayahs, totalResults, nil
+	completions := make([]domain.Completion, 0, limit)
+	for _, number := range SurahsWithPrefix(prefix, limit) {
+		completions = append(completions, domain.Completion{
+			Text:        SurahName(number),
+			Type:        domain.CompletionSurah,
+			SurahNumber: number,
+		})
+	}
+
+	terms, err := s.searchRepo.Complete(words[len(words)-1], limit-len(completions))
+	if err != nil {
+		return nil, err
+	}
+
+	lead := strings.Join(words[:len(words)-1], " ")
+	for _, term := range terms {
+		if lead != "" {
+			term.Text = lead + " " + term.Text
+		}
+		completions = append(completions, term)
+	}
+
+	return completions, nil
 }
 
-func getFieldKeys(fields map[string]any) []string {
-	keys := make([]string, 0, len(fields))
-	for k := range fields {
-		keys = append(keys, k)
+// nameFacets adds the surah names to the surah facet.
+func nameFacets(facets map[string][]domain.FacetCount) map[string][]domain.FacetCount {
+	for i, count := range facets[domain.FacetSurah] {
+		number, _ := strconv.Atoi(count.Value)
+		facets[domain.FacetSurah][i].Name = SurahName(number)
 	}
-	return keys
+	return facets
 }
 
-func splitDocumentID(id string) []string {
-	return strings.Split(id, ":")
+// NormalizeLocation maps the spellings of a revelation place to
+// domain.LocationMakkiyah or domain.LocationMadaniyah, returning an empty
+// string when it is neither.
+func NormalizeLocation(location string) string {
+	switch normalizeName(location) {
+	case "makiyah", "makah", "mekah", "meca", "mecan", "maki":
+		return domain.LocationMakkiyah
+	case "madaniyah", "madinah", "medina", "medinan", "madani":
+		return domain.LocationMadaniyah
+	}
+	return ""
 }
@@ -0,0 +1,248 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+
+	"github.com/anugrahsputra/go-quran-api/internal/domain"
+)
+
+// Judgment lists the verses a query should find, graded from 1 (somewhat
+// relevant) upwards. Verses are keyed by any reference ParseReference
+// accepts, e.g. "2:255" or "al-baqarah:255".
+type Judgment struct {
+	Query    string         `json:"query"`
+	Expected map[string]int `json:"expected"`
+}
+
+// QueryEvaluation holds the metrics of one judged query at the report's
+// cutoff.
+type QueryEvaluation struct {
+	Query          string  `json:"query"`
+	Precision      float64 `json:"precision"`
+	Recall         float64 `json:"recall"`
+	ReciprocalRank float64 `json:"reciprocal_rank"`
+	NDCG           float64 `json:"ndcg"`
+	// Missing are the expected verses not found within the cutoff.
+	Missing []string `json:"missing,omitempty"`
+}
+
+// EvaluationReport averages the query metrics over every judgment.
+type EvaluationReport struct {
+	K         int               `json:"k"`
+	Precision float64           `json:"precision"`
+	Recall    float64           `json:"recall"`
+	MRR       float64           `json:"mrr"`
+	NDCG      float64           `json:"ndcg"`
+	Queries   []QueryEvaluation `json:"queries"`
+}
+
+// Regression is a metric that dropped from the baseline by more than the
+// tolerance. Query is empty for the averaged metrics.
+type Regression struct {
+	Query    string
+	Metric   string
+	Baseline float64
+	Current  float64
+}
+
+func (r Regression) String() string {
+	scope := "overall"
+	if r.Query != "" {
+		scope = fmt.Sprintf("query %q", r.Query)
+	}
+	return fmt.Sprintf("%s %s: %.4f -> %.4f", scope, r.Metric, r.Baseline, r.Current)
+}
+
+// LoadJudgments reads a JSON array of judgments.
+func LoadJudgments(path string) ([]Judgment, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var judgments []Judgment
+	if err := json.Unmarshal(data, &judgments); err != nil {
+		return nil, fmt.Errorf("failed to parse judgments %s: %w", path, err)
+	}
+	for _, judgment := range judgments {
+		if judgment.Query == "" || len(judgment.Expected) == 0 {
+			return nil, fmt.Errorf("invalid judgment in %s: needs a query and expected verses", path)
+		}
+	}
+	return judgments, nil
+}
+
+// LoadEvaluationReport reads a report saved as a baseline.
+func LoadEvaluationReport(path string) (EvaluationReport, error) {
+	var report EvaluationReport
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return report, err
+	}
+	if err := json.Unmarshal(data, &report); err != nil {
+		return report, fmt.Errorf("failed to parse baseline %s: %w", path, err)
+	}
+	return report, nil
+}
+
+// SaveEvaluationReport writes a report to be used as a baseline.
+func SaveEvaluationReport(path string, report EvaluationReport) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// EvaluateRelevance runs every judged query through search, keeping the top
+// k hits, and scores the hits against the judgment.
+func EvaluateRelevance(search func(domain.SearchParams) (domain.SearchResults, error), judgments []Judgment, k int) (EvaluationReport, error) {
+	report := EvaluationReport{K: k}
+	if len(judgments) == 0 {
+		return report, nil
+	}
+
+	for _, judgment := range judgments {
+		grades, err := judgmentGrades(judgment)
+		if err != nil {
+			return report, err
+		}
+
+		results, err := search(domain.SearchParams{Query: judgment.Query, Page: 1, Limit: k, OmitTafsir: true})
+		if err != nil {
+			return report, fmt.Errorf("search %q failed: %w", judgment.Query, err)
+		}
+
+		ranked := make([]string, 0, k)
+		for _, hit := range results.Hits {
+			if len(ranked) == k {
+				break
+			}
+			ranked = append(ranked, domain.VerseRef{Surah: hit.SurahNumber, Ayah: hit.AyahNumber}.String())
+		}
+
+		evaluation := evaluateQuery(judgment.Query, ranked, grades, k)
+		report.Queries = append(report.Queries, evaluation)
+		report.Precision += evaluation.Precision
+		report.Recall += evaluation.Recall
+		report.MRR += evaluation.ReciprocalRank
+		report.NDCG += evaluation.NDCG
+	}
+
+	n := float64(len(report.Queries))
+	report.Precision /= n
+	report.Recall /= n
+	report.MRR /= n
+	report.NDCG /= n
+	return report, nil
+}
+
+// judgmentGrades normalizes the expected references of a judgment.
+func judgmentGrades(judgment Judgment) (map[string]int, error) {
+	grades := make(map[string]int, len(judgment.Expected))
+	for reference, grade := range judgment.Expected {
+		ref, ok := ParseReference(reference)
+		if !ok {
+			return nil, fmt.Errorf("invalid verse reference %q for query %q", reference, judgment.Query)
+		}
+		if grade < 1 {
+			return nil, fmt.Errorf("invalid grade %d for %q in query %q: must be at least 1", grade, reference, judgment.Query)
+		}
+		grades[ref.String()] = grade
+	}
+	return grades, nil
+}
+
+// evaluateQuery scores the ranked verse IDs of one query. nDCG uses the
+// exponential gain 2^grade - 1.
+func evaluateQuery(query string, ranked []string, grades map[string]int, k int) QueryEvaluation {
+	evaluation := QueryEvaluation{Query: query}
+
+	found := make(map[string]bool, len(grades))
+	dcg := 0.0
+	for i, id := range ranked {
+		grade, ok := grades[id]
+		if !ok {
+			continue
+		}
+		found[id] = true
+		if evaluation.ReciprocalRank == 0 {
+			evaluation.ReciprocalRank = 1 / float64(i+1)
+		}
+		dcg += gain(grade, i)
+	}
+
+	ideal := make([]int, 0, len(grades))
+	for id, grade := range grades {
+		ideal = append(ideal, grade)
+		if !found[id] {
+			evaluation.Missing = append(evaluation.Missing, id)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(ideal)))
+	idcg := 0.0
+	for i, grade := range ideal[:min(k, len(ideal))] {
+		idcg += gain(grade, i)
+	}
+
+	evaluation.Precision = float64(len(found)) / float64(k)
+	evaluation.Recall = float64(len(found)) / float64(len(grades))
+	if idcg > 0 {
+		evaluation.NDCG = dcg / idcg
+	}
+	sortVerseIDs(evaluation.Missing)
+	return evaluation
+}
+
+func gain(grade, rank int) float64 {
+	return (math.Pow(2, float64(grade)) - 1) / math.Log2(float64(rank+2))
+}
+
+// sortVerseIDs orders "surah:ayah" IDs in mushaf order.
+func sortVerseIDs(ids []string) {
+	sort.Slice(ids, func(i, j int) bool {
+		a, _ := ParseReference(ids[i])
+		b, _ := ParseReference(ids[j])
+		if a.Surah != b.Surah {
+			return a.Surah < b.Surah
+		}
+		return a.Ayah < b.Ayah
+	})
+}
+
+// CompareEvaluations lists the metrics that dropped from baseline by more
+// than tolerance, overall and per query. Queries missing from either
+// report are not compared.
+func CompareEvaluations(baseline, current EvaluationReport, tolerance float64) []Regression {
+	var regressions []Regression
+	check := func(query, metric string, before, after float64) {
+		if before-after > tolerance {
+			regressions = append(regressions, Regression{Query: query, Metric: metric, Baseline: before, Current: after})
+		}
+	}
+
+	check("", "precision", baseline.Precision, current.Precision)
+	check("", "recall", baseline.Recall, current.Recall)
+	check("", "mrr", baseline.MRR, current.MRR)
+	check("", "ndcg", baseline.NDCG, current.NDCG)
+
+	before := make(map[string]QueryEvaluation, len(baseline.Queries))
+	for _, evaluation := range baseline.Queries {
+		before[evaluation.Query] = evaluation
+	}
+	for _, after := range current.Queries {
+		evaluation, ok := before[after.Query]
+		if !ok {
+			continue
+		}
+		check(after.Query, "precision", evaluation.Precision, after.Precision)
+		check(after.Query, "recall", evaluation.Recall, after.Recall)
+		check(after.Query, "reciprocal_rank", evaluation.ReciprocalRank, after.ReciprocalRank)
+		check(after.Query, "ndcg", evaluation.NDCG, after.NDCG)
+	}
+	return regressions
+}
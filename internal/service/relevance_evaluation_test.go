@@ -0,0 +1,67 @@
+package service
+
+import (
+	"math"
+	"testing"
+
+	"github.com/anugrahsputra/go-quran-api/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluateRelevance(t *testing.T) {
+	hits := map[string][]domain.VerseRef{
+		"ayat kursi": {{Surah: 2, Ayah: 255}, {Surah: 2, Ayah: 256}},
+		"riba":       {{Surah: 2, Ayah: 1}, {Surah: 3, Ayah: 130}, {Surah: 2, Ayah: 2}},
+	}
+	search := func(params domain.SearchParams) (domain.SearchResults, error) {
+		assert.Equal(t, 3, params.Limit)
+		var results domain.SearchResults
+		for _, ref := range hits[params.Query] {
+			results.Hits = append(results.Hits, domain.SearchHit{SearchedAyah: domain.SearchedAyah{SurahNumber: ref.Surah, AyahNumber: ref.Ayah}})
+		}
+		return results, nil
+	}
+
+	report, err := EvaluateRelevance(search, []Judgment{
+		{Query: "ayat kursi", Expected: map[string]int{"al-baqarah:255": 3}},
+		{Query: "riba", Expected: map[string]int{"2:275": 3, "3:130": 2}},
+	}, 3)
+	require.NoError(t, err)
+	require.Len(t, report.Queries, 2)
+
+	kursi := report.Queries[0]
+	assert.InDelta(t, 1.0/3, kursi.Precision, 1e-9)
+	assert.Equal(t, 1.0, kursi.Recall)
+	assert.Equal(t, 1.0, kursi.ReciprocalRank)
+	assert.Equal(t, 1.0, kursi.NDCG)
+	assert.Empty(t, kursi.Missing)
+
+	riba := report.Queries[1]
+	assert.InDelta(t, 1.0/3, riba.Precision, 1e-9)
+	assert.Equal(t, 0.5, riba.Recall)
+	assert.Equal(t, 0.5, riba.ReciprocalRank)
+	assert.InDelta(t, (3/math.Log2(3))/(7+3/math.Log2(3)), riba.NDCG, 1e-9)
+	assert.Equal(t, []string{"2:275"}, riba.Missing)
+
+	assert.InDelta(t, 0.75, report.Recall, 1e-9)
+	assert.InDelta(t, 0.75, report.MRR, 1e-9)
+
+	_, err = EvaluateRelevance(search, []Judgment{{Query: "riba", Expected: map[string]int{"2:999": 1}}}, 3)
+	assert.Error(t, err)
+}
+
+func TestCompareEvaluations(t *testing.T) {
+	baseline := EvaluationReport{K: 10, MRR: 0.8, NDCG: 0.7, Queries: []QueryEvaluation{
+		{Query: "riba", ReciprocalRank: 1, NDCG: 0.9},
+		{Query: "zakat", ReciprocalRank: 0.5},
+	}}
+	current := EvaluationReport{K: 10, MRR: 0.795, NDCG: 0.75, Queries: []QueryEvaluation{
+		{Query: "riba", ReciprocalRank: 0.5, NDCG: 0.9},
+		{Query: "puasa", ReciprocalRank: 0},
+	}}
+
+	assert.Equal(t, []Regression{
+		{Query: "riba", Metric: "reciprocal_rank", Baseline: 1, Current: 0.5},
+	}, CompareEvaluations(baseline, current, 0.01))
+}
@@ -0,0 +1,296 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	searchAnalyticsPrefix = "quran:search:analytics"
+	// searchAnalyticsBucket is the period each set of statistics covers.
+	searchAnalyticsBucket = time.Hour
+	// searchAnalyticsTimeout bounds recording a search, which runs after
+	// the response, and building a report.
+	searchAnalyticsTimeout  = 2 * time.Second
+	maxAnalyticsQueryLength = 100
+	// searchAnalyticsQueue is how many searches may wait to be recorded.
+	// Searches beyond it are dropped, so a slow Redis never holds up or
+	// piles up behind searches.
+	searchAnalyticsQueue = 256
+)
+
+// SearchAnalyticsWindows are the periods a report can cover, each made of
+// the most recent hourly buckets including the current one.
+var SearchAnalyticsWindows = map[string]time.Duration{
+	"1h":  time.Hour,
+	"24h": 24 * time.Hour,
+	"7d":  7 * 24 * time.Hour,
+	"30d": 30 * 24 * time.Hour,
+}
+
+// ErrSearchAnalyticsDisabled is returned for reports when analytics are
+// not configured.
+var ErrSearchAnalyticsDisabled = errors.New("search analytics are not configured")
+
+var (
+	emailPattern  = regexp.MustCompile(`\S+@\S+`)
+	numberPattern = regexp.MustCompile(`\d{6,}`)
+)
+
+type QueryCount struct {
+	Query string `json:"query"`
+	Count int64  `json:"count"`
+}
+
+type SlowQuery struct {
+	Query string `json:"query"`
+	// LatencyMs is the slowest time the query took in the window.
+	LatencyMs float64 `json:"latency_ms"`
+}
+
+// SearchAnalyticsReport summarizes the searches recorded over a window.
+// AvgLatencyMs, SlowSearches and SlowQueries leave out searches answered
+// from the cache.
+type SearchAnalyticsReport struct {
+	Window             string       `json:"window"`
+	From               time.Time    `json:"from"`
+	Searches           int64        `json:"searches"`
+	ZeroResultSearches int64        `json:"zero_result_searches"`
+	ZeroResultRate     float64      `json:"zero_result_rate"`
+	AvgLatencyMs       float64      `json:"avg_latency_ms"`
+	SlowSearches       int64        `json:"slow_searches"`
+	TopQueries         []QueryCount `json:"top_queries"`
+	ZeroResultQueries  []QueryCount `json:"zero_result_queries"`
+	SlowQueries        []SlowQuery  `json:"slow_queries"`
+}
+
+// SearchAnalytics records the text, result count and latency of searches
+// in hourly Redis buckets, which expire after the retention period. Only
+// the normalized query is kept, with e-mail addresses and long numbers
+// masked, never who searched. A nil SearchAnalytics records nothing.
+type SearchAnalytics struct {
+	rc        *redis.Client
+	retention time.Duration
+	// slow is the latency from which a search counts as slow.
+	slow time.Duration
+	now  func() time.Time
+	// records holds the searches waiting for the worker to save them.
+	records chan searchRecord
+}
+
+// searchRecord is a search waiting to be recorded.
+type searchRecord struct {
+	query   string
+	total   int
+	latency time.Duration
+	// cached is set for searches answered from the cache, whose latency
+	// says nothing about the index and is not recorded.
+	cached bool
+}
+
+// NewSearchAnalytics returns analytics kept for retention, or nil when rc
+// is nil or retention is not positive. It starts the worker saving the
+// recorded searches.
+func NewSearchAnalytics(rc *redis.Client, retention, slow time.Duration) *SearchAnalytics {
+	if rc == nil || retention <= 0 {
+		return nil
+	}
+	a := &SearchAnalytics{rc: rc, retention: retention, slow: slow, now: time.Now, records: make(chan searchRecord, searchAnalyticsQueue)}
+	go a.run()
+	return a
+}
+
+func (a *SearchAnalytics) run() {
+	for r := range a.records {
+		a.save(r)
+	}
+}
+
+// anonymizeQuery normalizes a query and masks what could identify the
+// person searching.
+func anonymizeQuery(q string) string {
+	q = normalizeQuery(q)
+	q = emailPattern.ReplaceAllString(q, "<email>")
+	q = numberPattern.ReplaceAllString(q, "<number>")
+	if runes := []rune(q); len(runes) > maxAnalyticsQueryLength {
+		q = string(runes[:maxAnalyticsQueryLength])
+	}
+	return q
+}
+
+func (a *SearchAnalytics) key(bucket time.Time, kind string) string {
+	return fmt.Sprintf("%s:%d:%s", searchAnalyticsPrefix, bucket.Unix(), kind)
+}
+
+// record queues a search to be added to the current bucket, dropping it
+// when the queue is full.
+func (a *SearchAnalytics) record(r searchRecord) {
+	if a == nil {
+		return
+	}
+	select {
+	case a.records <- r:
+	default:
+	}
+}
+
+// save adds a search to the current bucket.
+func (a *SearchAnalytics) save(r searchRecord) {
+	query := anonymizeQuery(r.query)
+	if query == "" {
+		return
+	}
+	latency := r.latency
+
+	ctx, cancel := context.WithTimeout(context.Background(), searchAnalyticsTimeout)
+	defer cancel()
+
+	bucket := a.now().Truncate(searchAnalyticsBucket)
+	// Kept for a full retention period after the bucket ends.
+	expiry := a.retention + searchAnalyticsBucket
+	latencyMs := float64(latency.Microseconds()) / 1000
+
+	_, err := a.rc.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		totals := a.key(bucket, "totals")
+		pipe.HIncrBy(ctx, totals, "searches", 1)
+		if !r.cached {
+			pipe.HIncrBy(ctx, totals, "timed", 1)
+			pipe.HIncrBy(ctx, totals, "latency_us", latency.Microseconds())
+		}
+		pipe.Expire(ctx, totals, expiry)
+
+		queries := a.key(bucket, "queries")
+		pipe.ZIncrBy(ctx, queries, 1, query)
+		pipe.Expire(ctx, queries, expiry)
+
+		if r.total == 0 {
+			zero := a.key(bucket, "zero")
+			pipe.HIncrBy(ctx, totals, "zero_results", 1)
+			pipe.ZIncrBy(ctx, zero, 1, query)
+			pipe.Expire(ctx, zero, expiry)
+		}
+		if !r.cached && a.slow > 0 && latency >= a.slow {
+			slow := a.key(bucket, "slow")
+			pipe.HIncrBy(ctx, totals, "slow", 1)
+			pipe.ZAddGT(ctx, slow, redis.Z{Score: latencyMs, Member: query})
+			pipe.Expire(ctx, slow, expiry)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("Warning: Failed to record search analytics: %v", err)
+	}
+}
+
+// Report summarizes the named window of SearchAnalyticsWindows, listing
+// up to limit queries of each kind.
+func (a *SearchAnalytics) Report(window string, limit int) (SearchAnalyticsReport, error) {
+	report := SearchAnalyticsReport{Window: window}
+	if a == nil {
+		return report, ErrSearchAnalyticsDisabled
+	}
+	duration, ok := SearchAnalyticsWindows[window]
+	if !ok {
+		return report, fmt.Errorf("invalid window %q", window)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), searchAnalyticsTimeout)
+	defer cancel()
+
+	current := a.now().Truncate(searchAnalyticsBucket)
+	buckets := make([]time.Time, int(duration/searchAnalyticsBucket))
+	for i := range buckets {
+		buckets[i] = current.Add(-time.Duration(i) * searchAnalyticsBucket)
+	}
+	report.From = buckets[len(buckets)-1].UTC()
+
+	var latencyUs, timed int64
+	totals := make([]*redis.MapStringStringCmd, len(buckets))
+	_, err := a.rc.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, bucket := range buckets {
+			totals[i] = pipe.HGetAll(ctx, a.key(bucket, "totals"))
+		}
+		return nil
+	})
+	if err != nil {
+		return report, err
+	}
+	for _, cmd := range totals {
+		fields := cmd.Val()
+		report.Searches += parseCount(fields["searches"])
+		report.ZeroResultSearches += parseCount(fields["zero_results"])
+		report.SlowSearches += parseCount(fields["slow"])
+		latencyUs += parseCount(fields["latency_us"])
+		timed += parseCount(fields["timed"])
+	}
+	if report.Searches > 0 {
+		report.ZeroResultRate = float64(report.ZeroResultSearches) / float64(report.Searches)
+	}
+	if timed > 0 {
+		report.AvgLatencyMs = float64(latencyUs) / float64(timed) / 1000
+	}
+
+	top, err := a.merge(ctx, buckets, "queries", "SUM", limit)
+	if err != nil {
+		return report, err
+	}
+	zero, err := a.merge(ctx, buckets, "zero", "SUM", limit)
+	if err != nil {
+		return report, err
+	}
+	slow, err := a.merge(ctx, buckets, "slow", "MAX", limit)
+	if err != nil {
+		return report, err
+	}
+
+	report.TopQueries = queryCounts(top)
+	report.ZeroResultQueries = queryCounts(zero)
+	report.SlowQueries = make([]SlowQuery, 0, len(slow))
+	for _, z := range slow {
+		report.SlowQueries = append(report.SlowQueries, SlowQuery{Query: z.Member.(string), LatencyMs: z.Score})
+	}
+	return report, nil
+}
+
+// merge combines one kind of sorted set across buckets and returns its
+// highest limit members.
+func (a *SearchAnalytics) merge(ctx context.Context, buckets []time.Time, kind, aggregate string, limit int) ([]redis.Z, error) {
+	keys := make([]string, len(buckets))
+	for i, bucket := range buckets {
+		keys[i] = a.key(bucket, kind)
+	}
+	dest := fmt.Sprintf("%s:report:%s", searchAnalyticsPrefix, uuid.NewString())
+
+	var top *redis.ZSliceCmd
+	_, err := a.rc.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZUnionStore(ctx, dest, &redis.ZStore{Keys: keys, Aggregate: aggregate})
+		top = pipe.ZRevRangeWithScores(ctx, dest, 0, int64(limit-1))
+		pipe.Del(ctx, dest)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return top.Val(), nil
+}
+
+func queryCounts(members []redis.Z) []QueryCount {
+	counts := make([]QueryCount, 0, len(members))
+	for _, z := range members {
+		counts = append(counts, QueryCount{Query: z.Member.(string), Count: int64(z.Score)})
+	}
+	return counts
+}
+
+func parseCount(value string) int64 {
+	n, _ := strconv.ParseInt(value, 10, 64)
+	return n
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchAnalytics(t *testing.T) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+
+	analytics := NewSearchAnalytics(redis.NewClient(&redis.Options{Addr: mr.Addr()}), 30*24*time.Hour, 500*time.Millisecond)
+	now := time.Date(2025, 3, 10, 12, 30, 0, 0, time.UTC)
+	analytics.now = func() time.Time { return now }
+
+	analytics.save(searchRecord{query: "Sabar", total: 40, latency: 20 * time.Millisecond})
+	analytics.save(searchRecord{query: "sabar ", total: 40, latency: 600 * time.Millisecond})
+	analytics.save(searchRecord{query: "surga", total: 12, latency: 10 * time.Millisecond})
+	analytics.save(searchRecord{query: "zakat fitrah", total: 0, latency: 30 * time.Millisecond})
+
+	// Two hours earlier: inside 24h but outside 1h.
+	now = now.Add(-2 * time.Hour)
+	analytics.save(searchRecord{query: "zakat fitrah", total: 0, latency: 900 * time.Millisecond})
+	analytics.save(searchRecord{query: "sabar", total: 40, latency: 700 * time.Millisecond})
+	// Answered from the cache: counted, but its latency is not.
+	analytics.save(searchRecord{query: "surga", total: 12, latency: 2 * time.Second, cached: true})
+	now = now.Add(2 * time.Hour)
+
+	report, err := analytics.Report("24h", 10)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 3, 9, 13, 0, 0, 0, time.UTC), report.From)
+	assert.Equal(t, int64(7), report.Searches)
+	assert.Equal(t, int64(2), report.ZeroResultSearches)
+	assert.InDelta(t, 2.0/7, report.ZeroResultRate, 1e-9)
+	assert.InDelta(t, 376.67, report.AvgLatencyMs, 0.01)
+	assert.Equal(t, int64(3), report.SlowSearches)
+	assert.Equal(t, []QueryCount{{"sabar", 3}, {"zakat fitrah", 2}, {"surga", 2}}, report.TopQueries)
+	assert.Equal(t, []QueryCount{{"zakat fitrah", 2}}, report.ZeroResultQueries)
+	assert.Equal(t, []SlowQuery{{"zakat fitrah", 900}, {"sabar", 700}}, report.SlowQueries)
+
+	report, err = analytics.Report("1h", 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), report.Searches)
+	assert.Equal(t, []QueryCount{{"sabar", 2}}, report.TopQueries)
+	assert.Equal(t, []SlowQuery{{"sabar", 600}}, report.SlowQueries)
+
+	_, err = analytics.Report("2h", 10)
+	assert.Error(t, err)
+	_, err = (*SearchAnalytics)(nil).Report("24h", 10)
+	assert.ErrorIs(t, err, ErrSearchAnalyticsDisabled)
+
+	// Reports leave no keys behind.
+	for _, key := range mr.Keys() {
+		assert.NotContains(t, key, ":report:")
+	}
+}
+
+func TestSearchAnalytics_RecordDropsWhenFull(t *testing.T) {
+	// Without a worker nothing leaves the queue.
+	analytics := &SearchAnalytics{records: make(chan searchRecord, 1)}
+	analytics.record(searchRecord{query: "sabar"})
+	analytics.record(searchRecord{query: "surga"})
+
+	require.Len(t, analytics.records, 1)
+	assert.Equal(t, "sabar", (<-analytics.records).query)
+}
+
+func TestAnonymizeQuery(t *testing.T) {
+	assert.Equal(t, "sabar dan shalat", anonymizeQuery("  Sabar   DAN shalat "))
+	assert.Equal(t, "hubungi <email> atau <number>", anonymizeQuery("hubungi Foo@Example.com atau 081234567890"))
+	assert.Equal(t, "2:255 juz:30", anonymizeQuery("2:255 juz:30"))
+	assert.Len(t, []rune(anonymizeQuery(strings.Repeat("a", 200))), maxAnalyticsQueryLength)
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/anugrahsputra/go-quran-api/internal/domain"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	searchCacheGenerationKey = "quran:search:generation"
+	// searchCacheTimeout bounds each Redis call, so that a slow cache
+	// cannot hold up a search.
+	searchCacheTimeout = 200 * time.Millisecond
+)
+
+// SearchCacheStats reports how the search cache has been used since the
+// process started.
+type SearchCacheStats struct {
+	Enabled bool `json:"enabled"`
+	// Generation is the index generation current entries are keyed by.
+	Generation int64   `json:"generation"`
+	Hits       uint64  `json:"hits"`
+	Misses     uint64  `json:"misses"`
+	HitRate    float64 `json:"hit_rate"`
+}
+
+// SearchCache caches search results in Redis. Keys include an index
+// generation that is bumped whenever the index or the synonyms change, so
+// older entries are never read again and expire on their own. A nil
+// SearchCache caches nothing.
+type SearchCache struct {
+	rc     *redis.Client
+	ttl    time.Duration
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+// NewSearchCache returns a cache keeping results for ttl, or nil when rc
+// is nil or ttl is not positive.
+func NewSearchCache(rc *redis.Client, ttl time.Duration) *SearchCache {
+	if rc == nil || ttl <= 0 {
+		return nil
+	}
+	return &SearchCache{rc: rc, ttl: ttl}
+}
+
+// get looks params up, returning the key to store the results under on a
+// miss. The key is empty when params cannot be cached.
+func (c *SearchCache) get(params domain.SearchParams) (string, domain.SearchResults, bool) {
+	var results domain.SearchResults
+	if c == nil || params.Explain {
+		return "", results, false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), searchCacheTimeout)
+	defer cancel()
+
+	generation, err := c.generation(ctx)
+	if err != nil {
+		return "", results, false
+	}
+	key, err := searchCacheKey(generation, params)
+	if err != nil {
+		return "", results, false
+	}
+
+	val, err := c.rc.Get(ctx, key).Bytes()
+	if err == nil && json.Unmarshal(val, &results) == nil {
+		c.hits.Add(1)
+		return key, results, true
+	}
+	c.misses.Add(1)
+	return key, domain.SearchResults{}, false
+}
+
+func (c *SearchCache) set(key string, results domain.SearchResults) {
+	if c == nil || key == "" {
+		return
+	}
+
+	data, err := json.Marshal(results)
+	if err != nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), searchCacheTimeout)
+	defer cancel()
+	c.rc.Set(ctx, key, data, c.ttl)
+}
+
+// invalidate moves the cache to a new generation.
+func (c *SearchCache) invalidate() {
+	if c == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), searchCacheTimeout)
+	defer cancel()
+	if err := c.rc.Incr(ctx, searchCacheGenerationKey).Err(); err != nil {
+		log.Printf("Warning: Failed to invalidate the search cache: %v", err)
+	}
+}
+
+func (c *SearchCache) generation(ctx context.Context) (int64, error) {
+	generation, err := c.rc.Get(ctx, searchCacheGenerationKey).Int64()
+	if errors.Is(err, redis.Nil) {
+		return 0, nil
+	}
+	return generation, err
+}
+
+// Stats returns the hit and miss counts of this process.
+func (c *SearchCache) Stats() SearchCacheStats {
+	if c == nil {
+		return SearchCacheStats{}
+	}
+
+	stats := SearchCacheStats{Enabled: true, Hits: c.hits.Load(), Misses: c.misses.Load()}
+	if total := stats.Hits + stats.Misses; total > 0 {
+		stats.HitRate = float64(stats.Hits) / float64(total)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), searchCacheTimeout)
+	defer cancel()
+	stats.Generation, _ = c.generation(ctx)
+	return stats
+}
+
+// searchCacheKey hashes params after normalizing what does not change the
+// results: the case and spacing of the query and the order of fields and
+// facets.
+func searchCacheKey(generation int64, params domain.SearchParams) (string, error) {
+	params.Query = normalizeQuery(params.Query)
+	params.Page = max(params.Page, 1)
+	params.Fields = slices.Sorted(slices.Values(params.Fields))
+	params.Facets = slices.Sorted(slices.Values(params.Facets))
+
+	data, err := json.Marshal(params)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return fmt.Sprintf("quran:search:%d:%s", generation, hex.EncodeToString(sum[:16])), nil
+}
+
+// normalizeQuery lowercases q and collapses its spaces.
+func normalizeQuery(q string) string {
+	return strings.Join(strings.Fields(strings.ToLower(q)), " ")
+}
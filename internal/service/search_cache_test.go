@@ -0,0 +1,87 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/anugrahsputra/go-quran-api/internal/domain"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingSearchRepository returns one verse for every search and counts
+// the searches.
+type countingSearchRepository struct {
+	domain.QuranSearchRepository
+	searches int
+}
+
+func (r *countingSearchRepository) Search(params domain.SearchParams) (*domain.IndexResults, error) {
+	r.searches++
+	return &domain.IndexResults{
+		Total: 1,
+		Hits: []domain.IndexHit{{
+			SearchedAyah: domain.SearchedAyah{SurahNumber: 2, AyahNumber: 153, Translation: "sabar"},
+		}},
+	}, nil
+}
+
+func (r *countingSearchRepository) SpellingSuggestions(q string, limit int) ([]string, error) {
+	return []string{"sabr"}, nil
+}
+
+func TestSearchCache(t *testing.T) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+
+	repo := &countingSearchRepository{}
+	cache := NewSearchCache(redis.NewClient(&redis.Options{Addr: mr.Addr()}), time.Minute)
+	s := NewQuranSearchService(nil, nil, repo, nil, nil, nil, cache, nil).(*quranSearchService)
+
+	params := domain.SearchParams{Query: "sabar", Page: 1, Limit: 10, Fields: []string{"translation", "tafsir"}}
+	first, err := s.Search(params)
+	require.NoError(t, err)
+	assert.Equal(t, 1, repo.searches)
+
+	// The same search, written differently.
+	params.Query, params.Fields = "  Sabar ", []string{"tafsir", "translation"}
+	second, err := s.Search(params)
+	require.NoError(t, err)
+	assert.Equal(t, 1, repo.searches)
+	assert.Equal(t, first, second)
+
+	params.Page = 2
+	_, err = s.Search(params)
+	require.NoError(t, err)
+	assert.Equal(t, 2, repo.searches)
+
+	explained := params
+	explained.Explain = true
+	_, err = s.Search(explained)
+	require.NoError(t, err)
+	_, err = s.Search(explained)
+	require.NoError(t, err)
+	assert.Equal(t, 4, repo.searches)
+
+	s.cache.invalidate()
+	_, err = s.Search(params)
+	require.NoError(t, err)
+	assert.Equal(t, 5, repo.searches)
+
+	stats := s.SearchCacheStats()
+	assert.Equal(t, SearchCacheStats{Enabled: true, Generation: 1, Hits: 1, Misses: 3, HitRate: 0.25}, stats)
+
+	mr.FastForward(2 * time.Minute)
+	_, err = s.Search(params)
+	require.NoError(t, err)
+	assert.Equal(t, 6, repo.searches)
+}
+
+func TestNewSearchCache_Disabled(t *testing.T) {
+	assert.Nil(t, NewSearchCache(nil, time.Minute))
+	assert.Nil(t, NewSearchCache(redis.NewClient(&redis.Options{}), 0))
+	assert.Equal(t, SearchCacheStats{}, (*SearchCache)(nil).Stats())
+}
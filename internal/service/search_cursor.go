@@ -0,0 +1,58 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	"github.com/anugrahsputra/go-quran-api/internal/domain"
+)
+
+// searchCursor is encoded into the opaque cursor returned with results.
+// Sort records the sort order the values belong to.
+type searchCursor struct {
+	Sort  string   `json:"s"`
+	After []string `json:"a"`
+}
+
+// encodeCursor builds the cursor continuing after a hit with the given sort
+// values.
+func encodeCursor(sort []domain.SortField, after []string) string {
+	data, _ := json.Marshal(searchCursor{Sort: sortSignature(sort), After: after})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor reads a cursor, which has to come from a search with the
+// same sort order.
+func decodeCursor(cursor string, sort []domain.SortField) ([]string, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, syntaxError("invalid cursor")
+	}
+
+	var decoded searchCursor
+	if err := json.Unmarshal(data, &decoded); err != nil || len(decoded.After) == 0 {
+		return nil, syntaxError("invalid cursor")
+	}
+	if decoded.Sort != sortSignature(sort) {
+		return nil, syntaxError("cursor was created with a different sort order")
+	}
+	return decoded.After, nil
+}
+
+// sortSignature describes a sort order, relevance when empty.
+func sortSignature(sort []domain.SortField) string {
+	if len(sort) == 0 {
+		sort = []domain.SortField{{Field: domain.SortScore, Desc: true}}
+	}
+
+	parts := make([]string, len(sort))
+	for i, field := range sort {
+		order := "asc"
+		if field.Desc {
+			order = "desc"
+		}
+		parts[i] = field.Field + ":" + order
+	}
+	return strings.Join(parts, ",")
+}
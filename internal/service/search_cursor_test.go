@@ -0,0 +1,26 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/anugrahsputra/go-quran-api/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchCursor(t *testing.T) {
+	after := []string{"0.25", "2", "255"}
+
+	cursor := encodeCursor(nil, after)
+	decoded, err := decodeCursor(cursor, []domain.SortField{{Field: domain.SortScore, Desc: true}})
+	require.NoError(t, err)
+	assert.Equal(t, after, decoded)
+
+	var syntaxErr *QuerySyntaxError
+	_, err = decodeCursor(cursor, []domain.SortField{{Field: domain.SortSurah}})
+	assert.ErrorAs(t, err, &syntaxErr)
+	_, err = decodeCursor("not a cursor", nil)
+	assert.ErrorAs(t, err, &syntaxErr)
+	_, err = decodeCursor(encodeCursor(nil, nil), nil)
+	assert.ErrorAs(t, err, &syntaxErr)
+}
@@ -0,0 +1,162 @@
+package service
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/anugrahsputra/go-quran-api/internal/domain"
+)
+
+// QuerySyntaxError reports a search query that cannot be parsed.
+type QuerySyntaxError struct {
+	Message string
+}
+
+func (e *QuerySyntaxError) Error() string {
+	return "invalid search query: " + e.Message
+}
+
+func syntaxError(format string, args ...any) error {
+	return &QuerySyntaxError{Message: fmt.Sprintf(format, args...)}
+}
+
+// ParseSearchQuery parses the search syntax:
+//
+//	salat zakat          either word
+//	"orang beriman"      exact phrase
+//	+sabar -riba         required and excluded terms
+//	tafsir:riba          term scoped to one field, also tafsir:"riba"
+//	juz:30 page:1-3      number or range for juz and page
+//
+// Consecutive optional words searching the same fields are merged into a
+// single clause.
+func ParseSearchQuery(q string) ([]domain.QueryClause, error) {
+	runes := []rune(q)
+	var clauses []domain.QueryClause
+
+	for i := 0; i < len(runes); {
+		if unicode.IsSpace(runes[i]) {
+			i++
+			continue
+		}
+
+		clause := domain.QueryClause{Occur: domain.OccurShould}
+		switch runes[i] {
+		case '+':
+			clause.Occur = domain.OccurMust
+			i++
+		case '-':
+			clause.Occur = domain.OccurMustNot
+			i++
+		}
+		if clause.Occur != domain.OccurShould && (i == len(runes) || unicode.IsSpace(runes[i])) {
+			return nil, syntaxError("operator %q at position %d must be followed by a term", runes[i-1], i)
+		}
+
+		field, next, err := scanField(runes, i)
+		if err != nil {
+			return nil, err
+		}
+		clause.Field, i = field, next
+
+		if runes[i] == '"' {
+			start := i
+			end := slices.Index(runes[i+1:], '"')
+			if end < 0 {
+				return nil, syntaxError("unterminated phrase starting at position %d", start+1)
+			}
+			clause.Text = strings.TrimSpace(string(runes[i+1 : i+1+end]))
+			clause.Phrase = true
+			i += end + 2
+			if clause.Text == "" {
+				return nil, syntaxError("empty phrase at position %d", start+1)
+			}
+		} else {
+			start := i
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && runes[i] != '"' {
+				i++
+			}
+			clause.Text = string(runes[start:i])
+		}
+
+		if err := checkNumericClause(clause); err != nil {
+			return nil, err
+		}
+
+		if n := len(clauses); n > 0 && mergeable(clauses[n-1], clause) {
+			clauses[n-1].Text += " " + clause.Text
+			continue
+		}
+		clauses = append(clauses, clause)
+	}
+
+	if !slices.ContainsFunc(clauses, func(c domain.QueryClause) bool { return c.Occur != domain.OccurMustNot }) {
+		return nil, syntaxError("query must contain at least one term that is not excluded")
+	}
+
+	return clauses, nil
+}
+
+// scanField reads a "field:" prefix starting at i. Words that merely contain
+// a colon, such as verse references, are left alone; a letters-only prefix
+// that is not a known field is an error.
+func scanField(runes []rune, i int) (string, int, error) {
+	end := i
+	for end < len(runes) && (unicode.IsLetter(runes[end]) || runes[end] == '_') {
+		end++
+	}
+	if end == i || end+1 >= len(runes) || runes[end] != ':' || unicode.IsSpace(runes[end+1]) {
+		return "", i, nil
+	}
+
+	name := strings.ToLower(string(runes[i:end]))
+	if !slices.Contains(domain.SearchFields, name) {
+		return "", i, syntaxError("unknown field %q: must be one of %s", name, strings.Join(domain.SearchFields, ", "))
+	}
+	return name, end + 1, nil
+}
+
+func mergeable(a, b domain.QueryClause) bool {
+	return a.Occur == domain.OccurShould && b.Occur == domain.OccurShould &&
+		!a.Phrase && !b.Phrase && a.Field == b.Field &&
+		!slices.Contains(domain.NumericSearchFields, a.Field)
+}
+
+// checkNumericClause rejects a clause scoped to one of
+// domain.NumericSearchFields that is not a number or range.
+func checkNumericClause(clause domain.QueryClause) error {
+	if slices.Contains(domain.NumericSearchFields, clause.Field) && !isNumberRange(clause.Text) {
+		return syntaxError("field %q needs a number or a range such as 1-3, got %q", clause.Field, clause.Text)
+	}
+	return nil
+}
+
+// isNumberRange reports whether text is a positive number such as "3" or an
+// ascending range such as "1-3".
+func isNumberRange(text string) bool {
+	from, to, isRange := strings.Cut(text, "-")
+	lower, err := strconv.Atoi(from)
+	if err != nil || lower < 1 {
+		return false
+	}
+	if !isRange {
+		return true
+	}
+	upper, err := strconv.Atoi(to)
+	return err == nil && upper >= lower
+}
+
+// queryText returns the words of the clauses a hit has to match, for spelling
+// suggestions. Clauses repeated in another field are counted once.
+func queryText(clauses []domain.QueryClause) string {
+	var words []string
+	for _, clause := range clauses {
+		if clause.Occur != domain.OccurMustNot && !slices.Contains(words, clause.Text) {
+			words = append(words, clause.Text)
+		}
+	}
+	return strings.Join(words, " ")
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/anugrahsputra/go-quran-api/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSearchQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []domain.QueryClause
+	}{
+		{"orang beriman", []domain.QueryClause{
+			{Text: "orang beriman", Occur: domain.OccurShould},
+		}},
+		{`"orang beriman" sabar`, []domain.QueryClause{
+			{Text: "orang beriman", Phrase: true, Occur: domain.OccurShould},
+			{Text: "sabar", Occur: domain.OccurShould},
+		}},
+		{"+salat -riba zakat", []domain.QueryClause{
+			{Text: "salat", Occur: domain.OccurMust},
+			{Text: "riba", Occur: domain.OccurMustNot},
+			{Text: "zakat", Occur: domain.OccurShould},
+		}},
+		{`tafsir:riba Topic:"jual beli"`, []domain.QueryClause{
+			{Field: domain.FieldTafsir, Text: "riba", Occur: domain.OccurShould},
+			{Field: domain.FieldTopic, Text: "jual beli", Phrase: true, Occur: domain.OccurShould},
+		}},
+		{"sabab_nuzul:uhud juz:3-4 juz:30 surah:al-baqarah", []domain.QueryClause{
+			{Field: domain.FieldSababNuzul, Text: "uhud", Occur: domain.OccurShould},
+			{Field: domain.FieldJuz, Text: "3-4", Occur: domain.OccurShould},
+			{Field: domain.FieldJuz, Text: "30", Occur: domain.OccurShould},
+			{Field: domain.FieldSurah, Text: "al-baqarah", Occur: domain.OccurShould},
+		}},
+		{"2:255 orang-orang", []domain.QueryClause{
+			{Text: "2:255 orang-orang", Occur: domain.OccurShould},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			clauses, err := ParseSearchQuery(tt.query)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, clauses)
+		})
+	}
+}
+
+func TestParseSearchQuery_Invalid(t *testing.T) {
+	for _, query := range []string{
+		`"orang beriman`,
+		`""`,
+		"salat +",
+		"- riba",
+		"-riba",
+		"ayat:baqarah",
+		"juz:awal",
+		"page:5-2",
+		"juz:0",
+	} {
+		t.Run(query, func(t *testing.T) {
+			_, err := ParseSearchQuery(query)
+			var syntaxErr *QuerySyntaxError
+			assert.ErrorAs(t, err, &syntaxErr)
+		})
+	}
+}
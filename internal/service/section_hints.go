@@ -0,0 +1,96 @@
+package service
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/anugrahsputra/go-quran-api/internal/domain"
+)
+
+// sectionHints are words naming a tafsir section. A query mentioning one,
+// such as "sabab nuzul perang uhud", is about that section rather than
+// about the words themselves.
+var sectionHints = []struct {
+	words []string
+	field string
+}{
+	{words: []string{"asbabun", "nuzul"}, field: domain.FieldSababNuzul},
+	{words: []string{"asbab", "nuzul"}, field: domain.FieldSababNuzul},
+	{words: []string{"sabab", "nuzul"}, field: domain.FieldSababNuzul},
+	{words: []string{"sebab", "turun"}, field: domain.FieldSababNuzul},
+	{words: []string{"tafsir", "wajiz"}, field: domain.FieldWajiz},
+	{words: []string{"wajiz"}, field: domain.FieldWajiz},
+	{words: []string{"kosakata"}, field: domain.FieldKosakata},
+	{words: []string{"kesimpulan"}, field: domain.FieldConclusion},
+}
+
+// sectionHintWeight boosts the clause searching a section named in the
+// query.
+const sectionHintWeight = 4.0
+
+// applySectionHints removes section names from optional unscoped clauses
+// and searches the rest of the clause again in the named section, boosted.
+// Sections excluded by fields are left alone. The caller's clauses are not
+// changed.
+func applySectionHints(clauses []domain.QueryClause, fields []string) []domain.QueryClause {
+	clauses = slices.Clone(clauses)
+	var hinted []domain.QueryClause
+	for i, clause := range clauses {
+		if clause.Occur != domain.OccurShould || clause.Field != "" || clause.Phrase {
+			continue
+		}
+
+		words := strings.Fields(strings.ToLower(clause.Text))
+		var sections []string
+		for _, hint := range sectionHints {
+			if len(fields) > 0 && !slices.Contains(fields, hint.field) {
+				continue
+			}
+			if at := indexWords(words, hint.words); at >= 0 {
+				words = slices.Delete(words, at, at+len(hint.words))
+				sections = append(sections, hint.field)
+			}
+		}
+		// A query made only of section names still searches for them.
+		if len(sections) == 0 || len(words) == 0 {
+			continue
+		}
+
+		clauses[i].Text = strings.Join(words, " ")
+		for _, section := range sections {
+			hinted = append(hinted, domain.QueryClause{
+				Field: section,
+				Text:  clauses[i].Text,
+				Occur: domain.OccurShould,
+				Boost: sectionHintWeight,
+			})
+		}
+	}
+	return append(clauses, hinted...)
+}
+
+// indexWords returns where the words of hint appear in order in words, or
+// -1.
+func indexWords(words, hint []string) int {
+	for i := 0; i+len(hint) <= len(words); i++ {
+		if slices.Equal(words[i:i+len(hint)], hint) {
+			return i
+		}
+	}
+	return -1
+}
+
+// resolveSurahClauses replaces surah names in surah: clauses with the
+// surah number, so "surah:al-baqarah" matches like "surah:2".
+func resolveSurahClauses(clauses []domain.QueryClause) []domain.QueryClause {
+	for i, clause := range clauses {
+		if clause.Field != domain.FieldSurah {
+			continue
+		}
+		if number, ok := LookupSurah(clause.Text); ok {
+			clauses[i].Text = strconv.Itoa(number)
+		}
+	}
+	return clauses
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/anugrahsputra/go-quran-api/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplySectionHints(t *testing.T) {
+	tests := []struct {
+		name    string
+		clauses []domain.QueryClause
+		fields  []string
+		want    []domain.QueryClause
+	}{
+		{"section named", []domain.QueryClause{
+			{Text: "Sabab Nuzul perang uhud", Occur: domain.OccurShould},
+		}, nil, []domain.QueryClause{
+			{Text: "perang uhud", Occur: domain.OccurShould},
+			{Field: domain.FieldSababNuzul, Text: "perang uhud", Occur: domain.OccurShould, Boost: sectionHintWeight},
+		}},
+		{"alternative spelling", []domain.QueryClause{
+			{Text: "asbabun nuzul khamr", Occur: domain.OccurShould},
+		}, nil, []domain.QueryClause{
+			{Text: "khamr", Occur: domain.OccurShould},
+			{Field: domain.FieldSababNuzul, Text: "khamr", Occur: domain.OccurShould, Boost: sectionHintWeight},
+		}},
+		{"only the section name", []domain.QueryClause{
+			{Text: "sabab nuzul", Occur: domain.OccurShould},
+		}, nil, []domain.QueryClause{
+			{Text: "sabab nuzul", Occur: domain.OccurShould},
+		}},
+		{"phrase", []domain.QueryClause{
+			{Text: "sabab nuzul perang", Phrase: true, Occur: domain.OccurShould},
+		}, nil, []domain.QueryClause{
+			{Text: "sabab nuzul perang", Phrase: true, Occur: domain.OccurShould},
+		}},
+		{"section not searched", []domain.QueryClause{
+			{Text: "kosakata sabar", Occur: domain.OccurShould},
+		}, []string{domain.FieldTranslation}, []domain.QueryClause{
+			{Text: "kosakata sabar", Occur: domain.OccurShould},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := slices.Clone(tt.clauses)
+			assert.Equal(t, tt.want, applySectionHints(tt.clauses, tt.fields))
+			assert.Equal(t, original, tt.clauses)
+		})
+	}
+}
+
+func TestResolveSurahClauses(t *testing.T) {
+	clauses := resolveSurahClauses([]domain.QueryClause{
+		{Field: domain.FieldSurah, Text: "al-baqarah", Occur: domain.OccurMust},
+		{Field: domain.FieldSurah, Text: "sapi betina", Occur: domain.OccurShould},
+		{Text: "yunus", Occur: domain.OccurShould},
+	})
+
+	assert.Equal(t, "2", clauses[0].Text)
+	assert.Equal(t, "sapi betina", clauses[1].Text)
+	assert.Equal(t, "yunus", clauses[2].Text)
+}
@@ -14,18 +14,20 @@ import (
 
 type SurahService interface {
 	GetListSurah(ctx context.Context) ([]dto.SurahResp, error)
-	GetSurahDetail(ctx context.Context, id int, page int, limit int) (dto.SurahDetailData, int, int, error)
+	GetSurahDetail(ctx context.Context, id int, page int, limit int, translation string) (dto.SurahDetailData, int, int, error)
 }
 
 type surahService struct {
-	repo domain.SurahRepository
-	rc   *redis.Client
+	repo         domain.SurahRepository
+	rc           *redis.Client
+	translations ITranslationService
 }
 
-func NewSurahService(r domain.SurahRepository, rc *redis.Client) SurahService {
+func NewSurahService(r domain.SurahRepository, rc *redis.Client, ts ITranslationService) SurahService {
 	return &surahService{
-		repo: r,
-		rc:   rc,
+		repo:         r,
+		rc:           rc,
+		translations: ts,
 	}
 }
 
@@ -60,7 +62,27 @@ func (s *surahService) GetListSurah(ctx context.Context) ([]dto.SurahResp, error
 	return surahsResp, nil
 }
 
-func (s *surahService) GetSurahDetail(ctx context.Context, id int, page int, limit int) (dto.SurahDetailData, int, int, error) {
+// GetSurahDetail returns a page of verses with their text in translation,
+// which must be a resolved translation ID.
+func (s *surahService) GetSurahDetail(ctx context.Context, id int, page int, limit int, translation string) (dto.SurahDetailData, int, int, error) {
+	response, totalVerses, totalPages, err := s.getSurahDetail(ctx, id, page, limit)
+	if err != nil {
+		return response, totalVerses, totalPages, err
+	}
+
+	response.TranslationID = domain.DefaultTranslation
+	if translation != "" && translation != domain.DefaultTranslation {
+		response.TranslationID = translation
+		for i := range response.Verses {
+			ref := domain.VerseRef{Surah: response.SurahID, Ayah: response.Verses[i].Ayah}
+			response.Verses[i].Translation = s.translations.Verse(translation, ref)
+		}
+	}
+
+	return response, totalVerses, totalPages, nil
+}
+
+func (s *surahService) getSurahDetail(ctx context.Context, id int, page int, limit int) (dto.SurahDetailData, int, int, error) {
 	if page < 1 {
 		page = 1
 	}
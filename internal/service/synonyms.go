@@ -0,0 +1,170 @@
+package service
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/anugrahsputra/go-quran-api/internal/domain"
+)
+
+// defaultSynonyms groups common spellings and synonyms of Islamic terms in
+// Indonesian. Every word in a group expands to the others.
+var defaultSynonyms = [][]string{
+	{"salat", "shalat", "sholat", "solat", "sembahyang"},
+	{"zakat", "zakah"},
+	{"puasa", "saum", "shaum", "shiyam"},
+	{"haji", "hajj"},
+	{"allah", "tuhan"},
+	{"surga", "syurga", "jannah", "janah"},
+	{"neraka", "jahanam", "jahannam"},
+	{"kiamat", "qiyamah", "hari kiamat", "hari akhir", "hari kebangkitan"},
+	{"akhirat", "akherat"},
+	{"malaikat", "malaikah"},
+	{"setan", "syaitan", "syetan", "syaithan"},
+	{"quran", "alquran", "qur'an"},
+	{"kakbah", "kabah", "ka'bah"},
+	{"mekah", "makkah", "mekkah"},
+	{"madinah", "medinah"},
+	{"sedekah", "sadaqah", "shadaqah", "sodaqoh"},
+	{"infak", "infaq"},
+	{"wakaf", "waqaf"},
+	{"kurban", "qurban"},
+	{"taubat", "tobat", "taubah"},
+	{"takwa", "taqwa"},
+	{"wudu", "wudhu", "wudlu"},
+	{"tayamum", "tayammum"},
+	{"munafik", "munafiq"},
+	{"mukmin", "mu'min"},
+	{"syirik", "syirk"},
+	{"sabar", "shabar"},
+	{"ikhlas", "ikhlash"},
+	{"nikah", "kawin"},
+	{"talak", "talaq", "thalaq"},
+	{"khamar", "khamr", "arak"},
+	{"judi", "maisir", "maysir"},
+	{"fidyah", "fidiah"},
+	{"mahar", "maskawin"},
+}
+
+// SynonymStore expands query terms with their synonyms. It holds the
+// built-in list plus the groups from an optional file, which can be reloaded
+// while the server runs.
+type SynonymStore struct {
+	path string
+
+	mu       sync.RWMutex
+	synonyms map[string][]string
+}
+
+// NewSynonymStore loads the built-in synonyms and, when path is not empty,
+// the groups in that file. The file holds one comma-separated group per
+// line; blank lines and lines starting with # are ignored.
+func NewSynonymStore(path string) (*SynonymStore, error) {
+	s := &SynonymStore{path: path}
+	if _, err := s.Reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Reload rereads the synonym file and returns the number of groups loaded.
+// The previous synonyms are kept when the file cannot be read.
+func (s *SynonymStore) Reload() (int, error) {
+	groups := slices.Clone(defaultSynonyms)
+	if s.path != "" {
+		fileGroups, err := readSynonymFile(s.path)
+		if err != nil {
+			return 0, err
+		}
+		groups = append(groups, fileGroups...)
+	}
+
+	synonyms := make(map[string][]string)
+	for _, group := range groups {
+		for _, term := range group {
+			for _, other := range group {
+				if other != term && !slices.Contains(synonyms[term], other) {
+					synonyms[term] = append(synonyms[term], other)
+				}
+			}
+		}
+	}
+
+	s.mu.Lock()
+	s.synonyms = synonyms
+	s.mu.Unlock()
+
+	return len(groups), nil
+}
+
+func readSynonymFile(path string) ([][]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open synonym file: %w", err)
+	}
+	defer file.Close()
+
+	var groups [][]string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var group []string
+		for _, term := range strings.Split(line, ",") {
+			if term = normalizeSynonym(term); term != "" {
+				group = append(group, term)
+			}
+		}
+		if len(group) > 1 {
+			groups = append(groups, group)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read synonym file: %w", err)
+	}
+
+	return groups, nil
+}
+
+func normalizeSynonym(term string) string {
+	return strings.Join(strings.Fields(strings.ToLower(term)), " ")
+}
+
+// Expand fills in the Synonyms of each clause: synonyms of the whole clause
+// text, plus synonyms of each word for clauses that are not phrases.
+func (s *SynonymStore) Expand(clauses []domain.QueryClause) []domain.QueryClause {
+	if s == nil {
+		return clauses
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	expanded := make([]domain.QueryClause, len(clauses))
+	for i, clause := range clauses {
+		text := normalizeSynonym(clause.Text)
+		terms := []string{text}
+		var words []string
+		if !clause.Phrase {
+			words = strings.Fields(text)
+			terms = append(terms, words...)
+		}
+
+		for _, term := range terms {
+			for _, synonym := range s.synonyms[term] {
+				if synonym != text && !slices.Contains(words, synonym) && !slices.Contains(clause.Synonyms, synonym) {
+					clause.Synonyms = append(clause.Synonyms, synonym)
+				}
+			}
+		}
+		expanded[i] = clause
+	}
+	return expanded
+}
@@ -0,0 +1,34 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/anugrahsputra/go-quran-api/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSynonymStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "synonyms.txt")
+	require.NoError(t, os.WriteFile(path, []byte("# custom\nriba, Bunga Uang\n"), 0o644))
+
+	store, err := NewSynonymStore(path)
+	require.NoError(t, err)
+
+	clauses := store.Expand([]domain.QueryClause{
+		{Text: "Sholat jumat", Occur: domain.OccurShould},
+		{Text: "riba", Occur: domain.OccurMustNot},
+		{Text: "hari kiamat", Phrase: true, Occur: domain.OccurShould},
+	})
+	assert.Equal(t, []string{"salat", "shalat", "solat", "sembahyang"}, clauses[0].Synonyms)
+	assert.Equal(t, []string{"bunga uang"}, clauses[1].Synonyms)
+	assert.Equal(t, []string{"kiamat", "qiyamah", "hari akhir", "hari kebangkitan"}, clauses[2].Synonyms)
+
+	require.NoError(t, os.WriteFile(path, []byte("riba, renten\n"), 0o644))
+	groups, err := store.Reload()
+	require.NoError(t, err)
+	assert.Equal(t, len(defaultSynonyms)+1, groups)
+	assert.Equal(t, []string{"renten"}, store.Expand([]domain.QueryClause{{Text: "riba"}})[0].Synonyms)
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"strings"
+)
+
+const (
+	// minTafsirParagraph is the length below which a paragraph, often a
+	// heading or a verse quote, is joined to the next one.
+	minTafsirParagraph = 200
+	// maxTafsirParagraph is the length above which a paragraph is split
+	// between sentences.
+	maxTafsirParagraph = 1500
+)
+
+// SplitTafsir breaks a tafsir into paragraphs on line breaks, joining short
+// paragraphs to the following one and splitting long ones between
+// sentences.
+func SplitTafsir(tafsir string) []string {
+	var paragraphs []string
+	pending := ""
+	for _, line := range strings.Split(tafsir, "\n") {
+		line = strings.Join(strings.Fields(line), " ")
+		if line == "" {
+			continue
+		}
+		if pending != "" {
+			line = pending + " " + line
+		}
+		if len(line) < minTafsirParagraph {
+			pending = line
+			continue
+		}
+		pending = ""
+		paragraphs = append(paragraphs, splitLongParagraph(line)...)
+	}
+
+	if pending != "" {
+		if n := len(paragraphs); n > 0 && len(paragraphs[n-1])+len(pending) < maxTafsirParagraph {
+			paragraphs[n-1] += " " + pending
+		} else {
+			paragraphs = append(paragraphs, pending)
+		}
+	}
+	return paragraphs
+}
+
+func splitLongParagraph(paragraph string) []string {
+	var chunks []string
+	for len(paragraph) > maxTafsirParagraph {
+		cut := strings.LastIndex(paragraph[:maxTafsirParagraph], ". ")
+		if cut < minTafsirParagraph {
+			cut = strings.LastIndex(paragraph[:maxTafsirParagraph], " ")
+		}
+		if cut <= 0 {
+			break
+		}
+		chunks = append(chunks, strings.TrimSpace(paragraph[:cut+1]))
+		paragraph = strings.TrimSpace(paragraph[cut+1:])
+	}
+	return append(chunks, paragraph)
+}
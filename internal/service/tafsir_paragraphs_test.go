@@ -0,0 +1,38 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitTafsir(t *testing.T) {
+	long := strings.Repeat("Kalimat ini menjelaskan makna ayat dengan panjang. ", 60)
+	paragraph := strings.Repeat("a ", 120)
+
+	tests := []struct {
+		name   string
+		tafsir string
+		want   int
+	}{
+		{"empty", "", 0},
+		{"short heading joins next paragraph", "Kosakata\n" + paragraph, 1},
+		{"paragraphs", paragraph + "\n\n" + paragraph, 2},
+		{"short trailing paragraph joins previous", paragraph + "\nPenutup.", 1},
+		{"long paragraph is split", long, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paragraphs := SplitTafsir(tt.tafsir)
+			assert.Len(t, paragraphs, tt.want)
+			for _, p := range paragraphs {
+				assert.LessOrEqual(t, len(p), maxTafsirParagraph)
+				assert.Equal(t, strings.TrimSpace(p), p)
+			}
+		})
+	}
+
+	assert.Equal(t, []string{"Kosakata " + strings.TrimSpace(paragraph)}, SplitTafsir("Kosakata\n"+paragraph))
+}
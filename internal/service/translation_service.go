@@ -0,0 +1,61 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/anugrahsputra/go-quran-api/internal/domain"
+)
+
+type ITranslationService interface {
+	List() []domain.TranslationInfo
+	// Resolve picks the translation named by id, or else the first one in
+	// lang, or else domain.DefaultTranslation.
+	Resolve(lang, id string) (domain.TranslationInfo, error)
+	// Verse returns the text of a verse in a translation other than
+	// domain.DefaultTranslation.
+	Verse(id string, ref domain.VerseRef) string
+}
+
+type translationService struct {
+	repo domain.TranslationRepository
+}
+
+func NewTranslationService(repo domain.TranslationRepository) ITranslationService {
+	return &translationService{repo: repo}
+}
+
+func (s *translationService) List() []domain.TranslationInfo {
+	return s.repo.List()
+}
+
+func (s *translationService) Resolve(lang, id string) (domain.TranslationInfo, error) {
+	if id != "" {
+		info, ok := s.repo.Get(id)
+		if !ok {
+			// Accept "en-sahih" for "en.sahih".
+			info, ok = s.repo.Get(strings.Replace(id, "-", ".", 1))
+		}
+		if !ok {
+			return domain.TranslationInfo{}, fmt.Errorf("unknown translation %q", id)
+		}
+		return info, nil
+	}
+
+	if lang != "" {
+		for _, info := range s.repo.List() {
+			if strings.EqualFold(info.Language, lang) {
+				return info, nil
+			}
+		}
+		return domain.TranslationInfo{}, fmt.Errorf("no translation available for language %q: translations other than %s are loaded from TRANSLATIONS_DIR", lang, domain.DefaultTranslation)
+	}
+
+	info, _ := s.repo.Get(domain.DefaultTranslation)
+	return info, nil
+}
+
+func (s *translationService) Verse(id string, ref domain.VerseRef) string {
+	text, _ := s.repo.Verse(id, ref)
+	return text
+}
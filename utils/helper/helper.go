@@ -1,6 +1,11 @@
 package helper
 
-import "os"
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
 
 func GetEnv(key, defaultValue string) string {
 	getEnv := os.Getenv(key)
@@ -11,3 +16,35 @@ func GetEnv(key, defaultValue string) string {
 	}
 	return getEnv
 }
+
+func GetEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// GetEnvFloatMap reads a comma-separated list of key:value pairs such as
+// "translation:5,ngram:0.5", the format search requests use for boosts.
+func GetEnvFloatMap(key string) (map[string]float64, error) {
+	value := os.Getenv(key)
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+
+	values := make(map[string]float64)
+	for _, pair := range strings.Split(value, ",") {
+		name, number, ok := strings.Cut(pair, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid %s pair %q: must be key:value", key, pair)
+		}
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(number), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s pair %q: %q is not a number", key, pair, strings.TrimSpace(number))
+		}
+		values[strings.ToLower(name)] = parsed
+	}
+	return values, nil
+}
@@ -32,3 +32,10 @@ func AdminAuth() gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// IsAdmin reports whether the request carries the configured admin token,
+// for handlers that only unlock some options for admins.
+func IsAdmin(c *gin.Context) bool {
+	apiKey := os.Getenv("ADMIN_API_KEY")
+	return apiKey != "" && c.GetHeader("X-Admin-Token") == apiKey
+}
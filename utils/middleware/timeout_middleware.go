@@ -2,20 +2,30 @@ package middleware
 
 import (
 	"context"
+	"slices"
 	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
-func Timeout(timeout time.Duration) gin.HandlerFunc {
+// Timeout cancels the request context after timeout and answers 408 if
+// nothing was written by then. Routes listed in exempt, matched by their
+// full path such as "/api/v1/search/export", stream for longer and are
+// left without a deadline.
+func Timeout(timeout time.Duration, exempt ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if slices.Contains(exempt, c.FullPath()) {
+			c.Next()
+			return
+		}
+
 		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
 		defer cancel()
 
 		c.Request = c.Request.WithContext(ctx)
 		c.Next()
 
-		if ctx.Err() == context.DeadlineExceeded {
+		if ctx.Err() == context.DeadlineExceeded && !c.Writer.Written() {
 			c.AbortWithStatus(408)
 		}
 	}